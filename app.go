@@ -2,11 +2,10 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"io"
 	"net"
 	"os"
-	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/pressly/goose/v3"
@@ -14,7 +13,6 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/viper"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
-	"golang.org/x/crypto/ssh"
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
@@ -23,8 +21,14 @@ type App struct {
 	messageService *messageService
 	db             *sqlx.DB
 	configService  *configService
-	sshClient      *ssh.Client
-	listener       net.Listener
+	secretService  *secretService
+	hostKeyService *hostKeyService
+	sshAuthService *sshAuthService
+	tunnelManager  *tunnelManager
+	hostServer     *hostServer
+	linkManager    *linkManager
+	reconService   *reconService
+	safQueue       *safQueue
 }
 
 func NewApp() *App {
@@ -33,20 +37,29 @@ func NewApp() *App {
 
 type atmSwitch interface {
 	pack(message Message) ([]byte, error)
-	unpack(r io.Reader) (AtmResponse, error)
-	build(message *Message, reversal bool)
+	unpack(request Message, frame []byte) (AtmResponse, error)
+	build(message *Message, reversal bool) error
 	packEchoTest() ([]byte, error)
+	decode(frame []byte) (IsoMessage, error)
+	encode(message IsoMessage) ([]byte, error)
+	reconciliationMti(advice bool) string
 }
 
 func (a *App) shutdown(ctx context.Context) {
-	if a.db != nil {
-		a.db.Close()
+	if a.hostServer != nil && a.hostServer.status().Running {
+		a.hostServer.stop()
+	}
+	if a.safQueue != nil {
+		a.safQueue.stop()
 	}
-	if a.listener != nil {
-		a.listener.Close()
+	if a.linkManager != nil {
+		a.linkManager.closeAll()
 	}
-	if a.sshClient != nil {
-		a.sshClient.Close()
+	if a.tunnelManager != nil {
+		a.tunnelManager.stopAll()
+	}
+	if a.db != nil {
+		a.db.Close()
 	}
 }
 
@@ -95,6 +108,21 @@ func (a *App) startup(ctx context.Context) {
 
 	messageService := &messageService{db: db}
 	a.messageService = messageService
+	a.secretService = &secretService{db: db}
+	a.hostKeyService = newHostKeyService(ctx, dirname)
+	a.sshAuthService = &sshAuthService{ctx: ctx, secrets: a.secretService}
+	a.tunnelManager = newTunnelManager(ctx, db, a.sshAuthService, a.hostKeyService)
+	a.hostServer = newHostServer(ctx, db)
+	a.linkManager = newLinkManager(ctx, a.secretService, a.tunnelManager)
+	a.reconService = &reconService{db: db}
+	err = messageService.recoverReversals()
+	if err != nil {
+		log.Error().Err(err).Msg("")
+	}
+	a.safQueue = newSafQueue(ctx, db, messageService, func(target AtmSwitch, sw atmSwitch, b []byte) ([]byte, error) {
+		return exchange(a, target, sw, b)
+	})
+	a.safQueue.start()
 
 }
 
@@ -104,8 +132,24 @@ func sendMessage(a *App, message Message, reversal bool) (AtmResponse, error) {
 		log.Error().Err(err).Msg("")
 		return AtmResponse{}, err
 	}
-	atmSwitch.build(&message, reversal)
-	err = a.messageService.saveMessage(message)
+	if !reversal {
+		err = validateMessage(message)
+		if err != nil {
+			log.Error().Err(err).Msg("")
+			return AtmResponse{}, err
+		}
+	}
+	err = atmSwitch.build(&message, reversal)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return AtmResponse{}, err
+	}
+	err = encodePinBlocks(a.secretService, &message)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return AtmResponse{}, err
+	}
+	id, err := a.messageService.saveMessage(message)
 	if err != nil {
 		log.Error().Err(err).Msg("")
 		return AtmResponse{}, err
@@ -115,30 +159,93 @@ func sendMessage(a *App, message Message, reversal bool) (AtmResponse, error) {
 		log.Error().Err(err).Msg("")
 		return AtmResponse{}, err
 	}
-	serverAddr := fmt.Sprintf("%s:%s", viper.GetString("HOST"), viper.GetString("PORT"))
-	conn, err := net.DialTimeout("tcp", serverAddr, 30*time.Second)
+	response, err := exchange(a, message.Switch, atmSwitch, b)
 	if err != nil {
 		return AtmResponse{}, err
 	}
-	defer conn.Close()
-	err = a.messageService.sendTcpMessage(conn, b)
+	atmResponse, err := atmSwitch.unpack(message, response)
 	if err != nil {
+		log.Error().Err(err).Msg("")
+		return AtmResponse{}, err
+	}
+	err = a.messageService.saveResponseCode(id, atmResponse.ResponseCode)
+	if err != nil {
+		log.Error().Err(err).Msg("")
 		return AtmResponse{}, err
 	}
-	return atmSwitch.unpack(conn)
+	return atmResponse, nil
+}
+
+// errNoResponse means the host may have received the message.
+var errNoResponse = errors.New("no response")
+
+// exchange uses the persistent link when <SWITCH>_PERSISTENT is set.
+func exchange(a *App, target AtmSwitch, atmSwitch atmSwitch, b []byte) ([]byte, error) {
+	if isPersistent(target) {
+		return a.linkManager.exchange(target, atmSwitch, b)
+	}
+	endpoint, err := getEndpoint(target)
+	if err != nil {
+		return nil, err
+	}
+	frame, err := endpoint.framing.frame(b)
+	if err != nil {
+		return nil, err
+	}
+	return exchangeOnce(a, endpoint, frame)
+}
+
+// exchangeOnce sends a framed message on a new connection.
+func exchangeOnce(a *App, endpoint endpoint, frame []byte) ([]byte, error) {
+	d, err := newDialer(a.secretService, a.tunnelManager)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := endpoint.dial(d)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	err = a.messageService.sendTcpMessage(conn, frame, endpoint.timeout)
+	if err != nil {
+		return nil, err
+	}
+	response, err := endpoint.framing.read(conn)
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return nil, fmt.Errorf("%w from %s: %v", errNoResponse, conn.RemoteAddr(), err)
+	}
+	return response, err
 }
 
 func (a *App) SendFinancialMessage(message Message) (AtmResponse, error) {
 	return sendMessage(a, message, false)
 }
 
-func (a *App) SendReversalMessage(id int) (AtmResponse, error) {
-	message, err := a.messageService.getMessage(id)
+// SendReversalMessage reverses partially when options has a dispensed amount.
+func (a *App) SendReversalMessage(id int, options ReversalOptions) (AtmResponse, error) {
+	original, err := a.messageService.getMessage(id)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return AtmResponse{}, err
+	}
+	reversal, err := newReversal(original, options)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return AtmResponse{}, err
+	}
+	err = a.messageService.claimReversal(original)
 	if err != nil {
 		log.Error().Err(err).Msg("")
 		return AtmResponse{}, err
 	}
-	return sendMessage(a, message, true)
+	response, err := sendMessage(a, reversal, true)
+	status, responseCode := reversalOutcome(response, err)
+	settleErr := a.messageService.settleReversal(original.Id, status, responseCode)
+	if settleErr != nil {
+		log.Error().Err(settleErr).Msg("")
+	}
+	return response, err
 }
 
 func getAtmSwitch(message Message) (atmSwitch, error) {
@@ -171,11 +278,16 @@ func (a *App) GetConfigs() ([]Config, error) {
 		log.Error().Err(err).Msg("")
 		return nil, err
 	}
-	return configs, nil
+	return redactSecrets(configs), nil
 }
 
 func (a *App) UpdateConfigs(configs []Config) error {
-	err := a.configService.updateConfigs(configs)
+	configs, err := a.secretService.sealConfigs(configs)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	err = a.configService.updateConfigs(configs)
 	if err != nil {
 		log.Error().Err(err).Msg("")
 		return err
@@ -184,48 +296,40 @@ func (a *App) UpdateConfigs(configs []Config) error {
 }
 
 func (a *App) UseTunnel() error {
-	sshClient, listener, err := tunnel(a.ctx)
+	profile, err := a.configService.getActiveProfile()
 	if err != nil {
 		log.Error().Err(err).Msg("")
 		return err
 	}
-	a.sshClient = sshClient
-	a.listener = listener
-	log.Print("tunnel successful")
-	return nil
-}
-
-func (a *App) CloseTunnel() error {
-	if a.listener != nil {
-		err := a.listener.Close()
-		if err != nil {
-			log.Error().Err(err).Msg("")
-			return err
-		}
-
+	tunnels, err := a.tunnelManager.getTunnels(profile)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	if len(tunnels) == 0 {
+		err := fmt.Errorf("no tunnels configured for profile %s", profile)
+		log.Error().Err(err).Msg("")
+		return err
 	}
-	if a.sshClient != nil {
-		err := a.sshClient.Close()
+	for _, t := range tunnels {
+		err := a.tunnelManager.start(profile, t.Name)
 		if err != nil {
 			log.Error().Err(err).Msg("")
+			a.tunnelManager.stopAll()
 			return err
 		}
 	}
+	log.Print("tunnel successful")
+	return nil
+}
+
+func (a *App) CloseTunnel() error {
+	a.tunnelManager.stopAll()
 	return nil
 }
 
 func (a *App) PingTunnel() {
-	if a.sshClient != nil {
-		_, _, err := a.sshClient.SendRequest("keepalive@openssh.com", true, nil)
-		if err != nil {
-			log.Error().Err(err).Msg("")
-			runtime.EventsEmit(a.ctx, "tunnel", false, false)
-			return
-		}
-		runtime.EventsEmit(a.ctx, "tunnel", true, false)
-		return
-	}
-	runtime.EventsEmit(a.ctx, "tunnel", false, false)
+	runtime.EventsEmit(a.ctx, "tunnel", a.tunnelManager.ping(), false)
 }
 
 func (a *App) OpenFileDialog() (string, error) {
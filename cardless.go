@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Cardless data tags, each followed by a 2 digit length and the value.
+const (
+	cardlessReferenceTag = "01"
+	cardlessMobileTag    = "02"
+	cardlessOtpTag       = "03"
+)
+
+const (
+	maxReferenceCodeLength = 20
+	minMobileNumberLength  = 10
+	maxMobileNumberLength  = 15
+	minOtpLength           = 4
+	maxOtpLength           = 8
+)
+
+// serializeCardlessData leaves out the unstored OTP of reversals and advice
+// repeats.
+func serializeCardlessData(message Message) string {
+	data := ""
+	for _, v := range []struct{ tag, value string }{
+		{cardlessReferenceTag, message.ReferenceCode},
+		{cardlessMobileTag, message.MobileNumber},
+		{cardlessOtpTag, message.Otp},
+	} {
+		if len(v.value) > 0 {
+			data += fmt.Sprintf("%s%02d%s", v.tag, len(v.value), v.value)
+		}
+	}
+	return data
+}
+
+func parseCardlessData(data string) (map[string]string, error) {
+	values := make(map[string]string)
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return nil, fmt.Errorf("cardless data is truncated")
+		}
+		length, err := strconv.Atoi(data[2:4])
+		if err != nil || len(data) < 4+length {
+			return nil, fmt.Errorf("cardless data has an invalid length for tag %s", data[:2])
+		}
+		values[data[:2]] = data[4 : 4+length]
+		data = data[4+length:]
+	}
+	return values, nil
+}
+
+// readCardlessReference only reads cash-out responses since others may use
+// the field differently.
+func readCardlessReference(transaction Transaction, processCode string, data string) string {
+	if transaction != CARDLESS_WITHDRAW || !strings.HasPrefix(processCode, "01") {
+		return ""
+	}
+	values, err := parseCardlessData(data)
+	if err != nil {
+		return ""
+	}
+	return values[cardlessReferenceTag]
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCardlessData(t *testing.T) {
+	message := Message{ReferenceCode: "REF123", MobileNumber: "639171234567", Otp: "123456"}
+	data := serializeCardlessData(message)
+	if data != "0106REF1230212639171234567"+"0306123456" {
+		t.Errorf("serializeCardlessData() = %s", data)
+	}
+	message.Otp = ""
+	if data := serializeCardlessData(message); data != "0106REF1230212639171234567" {
+		t.Errorf("serializeCardlessData() without OTP = %s", data)
+	}
+	values, err := parseCardlessData(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{cardlessReferenceTag: "REF123", cardlessMobileTag: "639171234567", cardlessOtpTag: "123456"}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("parseCardlessData() = %v, want %v", values, want)
+	}
+	for _, invalid := range []string{"0106REF123020", "0110REF123", "01AAREF123"} {
+		if _, err := parseCardlessData(invalid); err == nil {
+			t.Errorf("parseCardlessData(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestReadCardlessReference(t *testing.T) {
+	tests := []struct {
+		transaction Transaction
+		processCode string
+		data        string
+		want        string
+	}{
+		{CARDLESS_WITHDRAW, "011000", "0106REF123", "REF123"},
+		{WITHDRAW, "011000", "0106REF123", ""},
+		{CARDLESS_WITHDRAW, "311000", "0106REF123", ""},
+		{CARDLESS_WITHDRAW, "011000", "0110REF", ""},
+	}
+	for _, tt := range tests {
+		if got := readCardlessReference(tt.transaction, tt.processCode, tt.data); got != tt.want {
+			t.Errorf("readCardlessReference(%s, %s, %q) = %q, want %q", tt.transaction, tt.processCode, tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestCardlessPrivateField(t *testing.T) {
+	tests := []struct {
+		atmSwitch AtmSwitch
+		field     string
+		empty     string
+	}{
+		{CORTEX, "48", "63"},
+		{NARADA, "63", "48"},
+		{POSTBRIDGE, "125", "48"},
+	}
+	for _, tt := range tests {
+		message := Message{
+			Switch:                   tt.atmSwitch,
+			Transaction:              CARDLESS_WITHDRAW,
+			Device:                   ATM,
+			Mti:                      "0200",
+			ProcessCode:              "011000",
+			TransactionAmount:        1000,
+			CurrencyCode:             PHP,
+			TransmissionDateTime:     "1019120000",
+			TraceNumber:              "123456",
+			LocalTransactionDateTime: "261019120000",
+			AcquiringInstitutionCode: "1234",
+			Rrn:                      "000000123456",
+			ReferenceCode:            "REF123",
+			MobileNumber:             "639171234567",
+		}
+		sw, err := getAtmSwitch(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := sw.pack(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := sw.decode(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := decoded.Fields[tt.field]; got != "0106REF1230212639171234567" {
+			t.Errorf("%s field %s = %q", tt.atmSwitch, tt.field, got)
+		}
+		if got := decoded.Fields[tt.empty]; got != "" {
+			t.Errorf("%s field %s = %q, want it empty", tt.atmSwitch, tt.empty, got)
+		}
+	}
+}
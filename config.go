@@ -16,24 +16,49 @@ type Config struct {
 	Value string `db:"value" json:"value,omitempty"`
 }
 
+const activeProfileKey = "ACTIVE_PROFILE"
+
 func (s *configService) getConfigs(keys ...string) ([]Config, error) {
 	config := []Config{}
+	profile, err := s.getActiveProfile()
+	if err != nil {
+		return config, err
+	}
 	if len(keys) > 0 {
-		query, args, _ := sqlx.In("SELECT * FROM config WHERE key IN (?);", keys)
+		query, args, _ := sqlx.In(`SELECT "key", "value" FROM profile_config WHERE profile = ? AND key IN (?);`, profile, keys)
 		query = s.db.Rebind(query)
 		err := s.db.Select(&config, query, args...)
 		return config, err
 	}
-	return s.loadConfigs()
+	return s.getProfileConfigs(profile)
 }
 
 func (s *configService) loadConfigs() ([]Config, error) {
 	config := []Config{}
-	err := s.db.Select(&config, "SELECT * FROM config")
+	err := s.db.Select(&config, `SELECT "key", "value" FROM config
+		UNION ALL
+		SELECT pc."key", pc."value" FROM profile_config pc
+		JOIN config c ON c."key" = $1 AND c."value" = pc.profile`, activeProfileKey)
+	return config, err
+}
+
+func (s *configService) getActiveProfile() (string, error) {
+	var profile string
+	err := s.db.Get(&profile, `SELECT "value" FROM config WHERE "key" = $1`, activeProfileKey)
+	return profile, err
+}
+
+func (s *configService) getProfileConfigs(profile string) ([]Config, error) {
+	config := []Config{}
+	err := s.db.Select(&config, `SELECT "key", "value" FROM profile_config WHERE profile = $1 ORDER BY rowid`, profile)
 	return config, err
 }
 
 func (s *configService) updateConfigs(configs []Config) error {
+	profile, err := s.getActiveProfile()
+	if err != nil {
+		return err
+	}
 	tx, _ := s.db.Begin()
 	oldConfig := make(map[string]string)
 	for _, c := range configs {
@@ -41,7 +66,7 @@ func (s *configService) updateConfigs(configs []Config) error {
 			c.Value = strings.ReplaceAll(c.Value, "\\", "/")
 		}
 		oldConfig[c.Key] = c.Value
-		_, err := tx.Exec("UPDATE config SET value = $1 WHERE key = $2", c.Value, c.Key)
+		_, err := tx.Exec(`UPDATE profile_config SET value = $1 WHERE profile = $2 AND key = $3`, c.Value, profile, c.Key)
 		if err != nil {
 			tx.Rollback()
 			for k, v := range oldConfig {
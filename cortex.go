@@ -3,13 +3,11 @@ package main
 import (
 	"errors"
 	"fmt"
-	"io"
 	"sort"
 
 	"github.com/moov-io/iso8583"
 	"github.com/moov-io/iso8583/encoding"
 	"github.com/moov-io/iso8583/field"
-	"github.com/moov-io/iso8583/network"
 	"github.com/rs/zerolog/log"
 )
 
@@ -62,6 +60,12 @@ var fisGlobalSpec = &iso8583.MessageSpec{
 			Enc:         encoding.BCD,
 			Pref:        BCDPrefixer.Fixed,
 		}),
+		10: field.NewString(&field.Spec{
+			Length:      8,
+			Description: "Conversion Rate, Cardholder Billing",
+			Enc:         encoding.BCD,
+			Pref:        BCDPrefixer.Fixed,
+		}),
 		11: field.NewString(&field.Spec{
 			Length:      6,
 			Description: "Trace Number",
@@ -74,6 +78,12 @@ var fisGlobalSpec = &iso8583.MessageSpec{
 			Enc:         encoding.BCD,
 			Pref:        BCDPrefixer.Fixed,
 		}),
+		15: field.NewString(&field.Spec{
+			Length:      4,
+			Description: "Settlement Date",
+			Enc:         encoding.BCD,
+			Pref:        BCDPrefixer.Fixed,
+		}),
 		26: field.NewString(&field.Spec{
 			Length:      4,
 			Description: "Merchant Code",
@@ -128,18 +138,36 @@ var fisGlobalSpec = &iso8583.MessageSpec{
 			Enc:         encoding.ASCII,
 			Pref:        BCDPrefixer.LLL,
 		}),
+		48: field.NewString(&field.Spec{
+			Length:      999,
+			Description: "Additional Data",
+			Enc:         encoding.ASCII,
+			Pref:        BCDPrefixer.LLL,
+		}),
 		49: field.NewString(&field.Spec{
 			Length:      3,
 			Description: "Transaction Currency Code",
 			Enc:         encoding.BCD,
 			Pref:        BCDPrefixer.Fixed,
 		}),
+		50: field.NewString(&field.Spec{
+			Length:      3,
+			Description: "Settlement Currency Code",
+			Enc:         encoding.BCD,
+			Pref:        BCDPrefixer.Fixed,
+		}),
 		51: field.NewString(&field.Spec{
 			Length:      3,
 			Description: "Card Holder Currency Code",
 			Enc:         encoding.BCD,
 			Pref:        BCDPrefixer.Fixed,
 		}),
+		52: field.NewString(&field.Spec{
+			Length:      16,
+			Description: "PIN Data",
+			Enc:         encoding.ASCII,
+			Pref:        BCDPrefixer.Fixed,
+		}),
 		54: field.NewString(&field.Spec{
 			Length:      999,
 			Description: "Account Balance",
@@ -152,6 +180,72 @@ var fisGlobalSpec = &iso8583.MessageSpec{
 			Enc:         encoding.BCD,
 			Pref:        BCDPrefixer.LL,
 		}),
+		66: field.NewString(&field.Spec{
+			Length:      1,
+			Description: "Settlement Code",
+			Enc:         encoding.BCD,
+			Pref:        BCDPrefixer.Fixed,
+		}),
+		74: field.NewString(&field.Spec{
+			Length:      10,
+			Description: "Credits Number",
+			Enc:         encoding.BCD,
+			Pref:        BCDPrefixer.Fixed,
+		}),
+		75: field.NewString(&field.Spec{
+			Length:      10,
+			Description: "Credits Reversal Number",
+			Enc:         encoding.BCD,
+			Pref:        BCDPrefixer.Fixed,
+		}),
+		76: field.NewString(&field.Spec{
+			Length:      10,
+			Description: "Debits Number",
+			Enc:         encoding.BCD,
+			Pref:        BCDPrefixer.Fixed,
+		}),
+		77: field.NewString(&field.Spec{
+			Length:      10,
+			Description: "Debits Reversal Number",
+			Enc:         encoding.BCD,
+			Pref:        BCDPrefixer.Fixed,
+		}),
+		86: field.NewString(&field.Spec{
+			Length:      16,
+			Description: "Credits Amount",
+			Enc:         encoding.BCD,
+			Pref:        BCDPrefixer.Fixed,
+		}),
+		87: field.NewString(&field.Spec{
+			Length:      16,
+			Description: "Credits Reversal Amount",
+			Enc:         encoding.BCD,
+			Pref:        BCDPrefixer.Fixed,
+		}),
+		88: field.NewString(&field.Spec{
+			Length:      16,
+			Description: "Debits Amount",
+			Enc:         encoding.BCD,
+			Pref:        BCDPrefixer.Fixed,
+		}),
+		89: field.NewString(&field.Spec{
+			Length:      16,
+			Description: "Debits Reversal Amount",
+			Enc:         encoding.BCD,
+			Pref:        BCDPrefixer.Fixed,
+		}),
+		95: field.NewString(&field.Spec{
+			Length:      42,
+			Description: "Replacement Amounts",
+			Enc:         encoding.ASCII,
+			Pref:        BCDPrefixer.Fixed,
+		}),
+		97: field.NewString(&field.Spec{
+			Length:      17,
+			Description: "Net Settlement Amount",
+			Enc:         encoding.ASCII,
+			Pref:        BCDPrefixer.Fixed,
+		}),
 		100: field.NewString(&field.Spec{
 			Length:      99,
 			Description: "Receiving Code",
@@ -177,6 +271,14 @@ func (s *cortexSwitch) getMti(message Message, reversal bool) string {
 	if reversal {
 		return fmt.Sprintf("1%s", FinancialReversal)
 	}
+	switch message.Transaction {
+	case PRE_AUTH, INCREMENTAL_AUTH:
+		return fmt.Sprintf("1%s", AuthorizationRequest)
+	case PRE_AUTH_COMPLETION:
+		return fmt.Sprintf("1%s", FinancialAdvice)
+	case PRE_AUTH_CANCEL:
+		return fmt.Sprintf("1%s", FinancialReversal)
+	}
 	switch message.Channel {
 	case MASTERCARD:
 		return fmt.Sprintf("1%s", FinancialRequestMasterVisa)
@@ -185,17 +287,32 @@ func (s *cortexSwitch) getMti(message Message, reversal bool) string {
 	}
 }
 
-func (s *cortexSwitch) getProcessCode(message Message) string {
+func (s *cortexSwitch) reconciliationMti(advice bool) string {
+	if advice {
+		return fmt.Sprintf("1%s", AcquirerReconciliationAdvice)
+	}
+	return fmt.Sprintf("1%s", AcquirerReconciliationRequest)
+}
+
+func (s *cortexSwitch) getProcessCode(message Message) (string, error) {
 	var processCode string
 	transaction := message.Transaction
 	device := message.Device
 	switch transaction {
-	case PURCHASE, ELOAD:
+	case PURCHASE, ELOAD, PRE_AUTH, INCREMENTAL_AUTH, PRE_AUTH_COMPLETION, PRE_AUTH_CANCEL:
 		processCode = "00"
-	case WITHDRAW:
+	case WITHDRAW, CARDLESS_WITHDRAW:
 		processCode = "01"
 	case IBFTD:
 		processCode = "10"
+	case MINI_STATEMENT:
+		processCode = "38"
+	case PIN_CHANGE:
+		processCode = "96"
+	case DEPOSIT:
+		processCode = "21"
+	case CHEQUE_DEPOSIT:
+		processCode = "24"
 	case IBFTC:
 		if message.TargetBank == INTER_SYSTEM {
 			processCode = "26"
@@ -213,30 +330,37 @@ func (s *cortexSwitch) getProcessCode(message Message) string {
 	case BILLS:
 		processCode = "51"
 	default:
-		panic("Unable to get Process Code")
+		return "", fmt.Errorf("unable to get process code for transaction %s", transaction)
 	}
 
-	return processCode
+	return processCode, nil
 }
 
 var cortex = &cortexSwitch{
 	spec: *fisGlobalSpec,
 }
 
-func (s *cortexSwitch) build(message *Message, reversal bool) {
+func (s *cortexSwitch) build(message *Message, reversal bool) error {
 	originalMti := message.Mti
 	message.Mti = s.getMti(*message, reversal)
 	if reversal {
-		originalDataElements := s.serializeOriginalDataElements(originalMti, message.TraceNumber, message.LocalTransactionDateTime, padLeftWithZeros(message.AcquiringInstitutionCode, 10))
+		originalDataElements := s.serializeOriginalDataElements(originalMti, message.TraceNumber, message.LocalTransactionDateTime, message.ReversalReason, padLeftWithZeros(message.AcquiringInstitutionCode, 10))
 		message.OriginalDataElements = originalDataElements
-		message.Transaction = "REVERSAL " + message.Transaction
-		return
+		return nil
+	}
+	if message.AuthorizationId > 0 {
+		message.OriginalDataElements = s.serializeOriginalDataElements(originalMti, message.TraceNumber, message.LocalTransactionDateTime, followUpReason(*message), padLeftWithZeros(message.AcquiringInstitutionCode, 10))
 	}
 	message.TransmissionDateTime = generateTransmissionDateTime()
 	message.TraceNumber = generateStan()
 	message.Rrn = generateRrn()
 	message.LocalTransactionDateTime = generateLocalTransactionDateTime(message.TransmissionDateTime)
-	message.ProcessCode = s.getProcessCode(*message) + "0000"
+	processCode, err := s.getProcessCode(*message)
+	if err != nil {
+		return err
+	}
+	message.ProcessCode = processCode + "0000"
+	return nil
 }
 
 func (s *cortexSwitch) pack(message Message) ([]byte, error) {
@@ -249,13 +373,29 @@ func (s *cortexSwitch) pack(message Message) ([]byte, error) {
 	}
 
 	isoMesage.Field(3, message.ProcessCode)
-	isoMesage.Field(4, padLeftWithZeros(moveDecimalRight(message.TransactionAmount), fisGlobalSpec.Fields[4].Spec().Length))
-	isoMesage.Field(6, padLeftWithZeros(moveDecimalRight(message.TransactionAmount), fisGlobalSpec.Fields[6].Spec().Length))
+	// partial reversals carry the dispensed amount in field 4 and the original
+	// in field 30 instead of field 95
+	amount := message.TransactionAmount
+	if message.ReplacementAmount > 0 {
+		amount = message.ReplacementAmount
+	}
+	isoMesage.Field(4, padLeftWithZeros(moveDecimalRight(amount, message.CurrencyCode), fisGlobalSpec.Fields[4].Spec().Length))
+	billing, billingCurrency := billingAmount(message)
+	isoMesage.Field(6, padLeftWithZeros(moveDecimalRight(billing, billingCurrency), fisGlobalSpec.Fields[6].Spec().Length))
 	isoMesage.Field(7, message.TransmissionDateTime)
+
+	if message.ConversionRate > 0 {
+		rate, err := serializeConversionRate(message.ConversionRate)
+		if err != nil {
+			return nil, err
+		}
+		isoMesage.Field(10, rate)
+	}
+
 	isoMesage.Field(11, message.TraceNumber)
 	isoMesage.Field(12, message.LocalTransactionDateTime)
 	isoMesage.Field(26, string(message.Device))
-	isoMesage.Field(30, padLeftWithZeros(moveDecimalRight(message.TransactionAmount), fisGlobalSpec.Fields[4].Spec().Length))
+	isoMesage.Field(30, padLeftWithZeros(moveDecimalRight(message.TransactionAmount, message.CurrencyCode), fisGlobalSpec.Fields[4].Spec().Length))
 	isoMesage.Field(32, padLeftWithZeros(message.AcquiringInstitutionCode, 10))
 	isoMesage.Field(37, message.Rrn)
 
@@ -267,13 +407,21 @@ func (s *cortexSwitch) pack(message Message) ([]byte, error) {
 		isoMesage.Field(43, string(message.TerminalNameAndLocation))
 	}
 
-	isoMesage.Field(46, serializeCortexFee(message.TransactionFee))
+	isoMesage.Field(46, serializeCortexFee(message.TransactionFee, message.CurrencyCode))
+
+	if data := serializeAdditionalData(message); len(data) > 0 {
+		isoMesage.Field(48, data)
+	}
 
 	if len(message.CurrencyCode) > 0 {
 		isoMesage.Field(49, string(message.CurrencyCode))
 	}
 
-	isoMesage.Field(51, string(PHP))
+	isoMesage.Field(51, string(billingCurrency))
+
+	if len(message.PinBlock) > 0 {
+		isoMesage.Field(52, message.PinBlock)
+	}
 
 	if len(message.OriginalDataElements) > 0 {
 		isoMesage.Field(56, message.OriginalDataElements)
@@ -310,44 +458,31 @@ func (s *cortexSwitch) pack(message Message) ([]byte, error) {
 	}
 
 	headerBytes := []byte(header)
-	result := append(headerBytes, rawMessage...)
-	originalLength := len(result)
-	lengthPrefix := make([]byte, 2)
-	lengthValue := int16(originalLength)
-	lengthPrefix[0] = byte(lengthValue >> 8)
-	lengthPrefix[1] = byte(lengthValue)
-	withPrefix := append(lengthPrefix, result...)
-	return withPrefix, err
+	return append(headerBytes, rawMessage...), nil
 }
 
-func (s *cortexSwitch) unpack(r io.Reader) (AtmResponse, error) {
-	lengthBuffer := network.NewBinary2BytesHeader()
-	lengthBuffer.ReadFrom(r)
-	length := lengthBuffer.Len
-	response := make([]byte, length)
-	n, err := io.ReadFull(r, response)
-	if err != nil {
-		return AtmResponse{}, err
-	}
-	if n < 21 {
-		return AtmResponse{}, err
+func (s *cortexSwitch) unpack(request Message, response []byte) (AtmResponse, error) {
+	if len(response) < len(header) {
+		return AtmResponse{}, errors.New("frame is shorter than the Cortex header")
 	}
-	header := make([]byte, 21)
-	copy(header, response[:21])
 	responseMessage := iso8583.NewMessage(fisGlobalSpec)
-	unpacked := make([]byte, length)
-	copy(unpacked, response[21:])
-	responseMessage.Unpack(unpacked)
+	responseMessage.Unpack(response[len(header):])
 	traceNumber, _ := responseMessage.GetField(11).String()
 	responseCode, _ := responseMessage.GetField(39).String()
 	rrn, _ := responseMessage.GetField(37).String()
 	balanceField, _ := responseMessage.GetField(54).String()
 	balance := balanceDeserializer(balanceField)
+	processCode, _ := responseMessage.GetField(3).String()
+	currency, _ := responseMessage.GetField(49).String()
+	additionalData, _ := responseMessage.GetField(48).String()
 	atmResponse := AtmResponse{
-		TraceNumber:  traceNumber,
-		ResponseCode: responseCode,
-		RRN:          rrn,
-		Balance:      fmt.Sprintf("%.2f", balance),
+		TraceNumber:   traceNumber,
+		ResponseCode:  responseCode,
+		RRN:           rrn,
+		Balance:       balance,
+		Statement:     readMiniStatement(processCode, Currency(currency), additionalData),
+		ReferenceCode: readCardlessReference(request.Transaction, processCode, additionalData),
+		Currency:      Currency(currency),
 	}
 	keys := make([]int, 0, len(responseMessage.GetFields()))
 
@@ -364,15 +499,30 @@ func (s *cortexSwitch) unpack(r io.Reader) (AtmResponse, error) {
 	return atmResponse, nil
 }
 
-func serializeCortexFee(fee float64) string {
-	serializedFee := fmt.Sprint("00608D-", padLeftWithZeros(moveDecimalRight(fee), 7))
+func serializeCortexFee(fee float64, currency Currency) string {
+	serializedFee := fmt.Sprint("00", currency, "D-", padLeftWithZeros(moveDecimalRight(fee, currency), 7))
 	return addTrailingSpaces(serializedFee, 34)
 }
 
-func (s *cortexSwitch) serializeOriginalDataElements(mti string, traceNumber string, localTransactionDateTime string, acquiringCode string) string {
-	return fmt.Sprint(mti, traceNumber, localTransactionDateTime, "01", acquiringCode)
+func (s *cortexSwitch) serializeOriginalDataElements(mti string, traceNumber string, localTransactionDateTime string, reason ReversalReason, acquiringCode string) string {
+	return fmt.Sprint(mti, traceNumber, localTransactionDateTime, reason, acquiringCode)
 }
 
 func (s *cortexSwitch) packEchoTest() ([]byte, error) {
 	return nil, errors.New("echo test not supported")
 }
+
+func (s *cortexSwitch) decode(frame []byte) (IsoMessage, error) {
+	if len(frame) < len(header) {
+		return IsoMessage{}, errors.New("frame is shorter than the Cortex header")
+	}
+	return decodeIso(fisGlobalSpec, frame[len(header):])
+}
+
+func (s *cortexSwitch) encode(message IsoMessage) ([]byte, error) {
+	rawMessage, err := encodeIso(fisGlobalSpec, message)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(header), rawMessage...), nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import "testing"
+
+func TestSerializeConversionRate(t *testing.T) {
+	tests := []struct {
+		rate float64
+		want string
+	}{
+		{0.3871, "73871000"},
+		{1, "61000000"},
+		{56.25, "55625000"},
+		{1234567.8, "01234568"},
+	}
+	for _, tt := range tests {
+		got, err := serializeConversionRate(tt.rate)
+		if err != nil || got != tt.want {
+			t.Errorf("serializeConversionRate(%v) = %s, %v, want %s", tt.rate, got, err, tt.want)
+		}
+	}
+	for _, rate := range []float64{0, -1, 0.00000001, 10000000} {
+		if _, err := serializeConversionRate(rate); err == nil {
+			t.Errorf("serializeConversionRate(%v) should fail", rate)
+		}
+	}
+}
+
+func TestMoveDecimalRight(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		currency Currency
+		want     string
+	}{
+		{19.99, USD, "1999"},
+		{0.125, PHP, "13"},
+		{1300, "392", "1300"},
+		{1.234, "048", "1234"},
+	}
+	for _, tt := range tests {
+		if got := moveDecimalRight(tt.amount, tt.currency); got != tt.want {
+			t.Errorf("moveDecimalRight(%v, %s) = %s, want %s", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestBillingAmount(t *testing.T) {
+	tests := []struct {
+		message  Message
+		want     float64
+		currency Currency
+	}{
+		{Message{TransactionAmount: 1000, CurrencyCode: PHP}, 1000, PHP},
+		{Message{TransactionAmount: 1000, CurrencyCode: PHP, BillingAmount: 17.5, BillingCurrencyCode: USD, ConversionRate: 0.0178}, 17.5, USD},
+		{Message{TransactionAmount: 1000, CurrencyCode: PHP, BillingCurrencyCode: USD, ConversionRate: 0.017853}, 17.85, USD},
+		{Message{TransactionAmount: 1000, CurrencyCode: PHP, BillingCurrencyCode: "392", ConversionRate: 2.6156}, 2616, "392"},
+	}
+	for _, tt := range tests {
+		got, currency := billingAmount(tt.message)
+		if got != tt.want || currency != tt.currency {
+			t.Errorf("billingAmount(%+v) = %v %s, want %v %s", tt.message, got, currency, tt.want, tt.currency)
+		}
+	}
+}
+
+func TestBalanceDeserializer(t *testing.T) {
+	tests := map[string]string{
+		"":                     "0.00",
+		"1002608C000000123456": "1234.56",
+		"1002392C000000123456": "123456",
+		"1002048C000000123456": "123.456",
+	}
+	for input, want := range tests {
+		if got := balanceDeserializer(input); got != want {
+			t.Errorf("balanceDeserializer(%q) = %s, want %s", input, got, want)
+		}
+	}
+}
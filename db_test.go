@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pressly/goose/v3"
+)
+
+func newTestDB(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Connect("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	goose.SetBaseFS(embedMigrations)
+	goose.SetLogger(goose.NopLogger())
+	if err := goose.SetDialect("sqlite"); err != nil {
+		t.Fatal(err)
+	}
+	if err := goose.Up(db.DB, "migrations"); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// newTestMessage returns a Cortex withdrawal with every field the database
+// requires.
+func newTestMessage() Message {
+	return Message{
+		Switch:                   CORTEX,
+		Transaction:              WITHDRAW,
+		Channel:                  ON_US,
+		Device:                   ATM,
+		Mti:                      "1200",
+		ProcessCode:              "011000",
+		TransactionAmount:        1000,
+		CurrencyCode:             PHP,
+		TransmissionDateTime:     "1019120000",
+		TraceNumber:              "123456",
+		LocalTransactionDateTime: "261019120000",
+		AcquiringInstitutionCode: "1234",
+		TerminalID:               "TERM0001",
+		Rrn:                      "000000123456",
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DepositCompletion is what the device counted.
+type DepositCompletion struct {
+	CountedAmount float64 `json:"countedAmount"`
+	Items         int     `json:"items"`
+}
+
+func isDeposit(transaction Transaction) bool {
+	return transaction == DEPOSIT || transaction == CHEQUE_DEPOSIT
+}
+
+// serializeDepositData formats the item count and the cheque number.
+func serializeDepositData(message Message) string {
+	return fmt.Sprintf("%03d%s", message.DepositItems, message.ChequeNumber)
+}
+
+// newDepositCompletion completes an approved deposit request once, unless
+// it was reversed.
+func (s *messageService) newDepositCompletion(id int, completion DepositCompletion) (Message, error) {
+	request, err := s.getMessage(id)
+	if err != nil {
+		return Message{}, err
+	}
+	if !isDeposit(request.Transaction) || request.AuthorizationId > 0 || request.ParentId > 0 {
+		return Message{}, fmt.Errorf("message %d is not a deposit request", id)
+	}
+	if !approved(request.ResponseCode) {
+		return Message{}, fmt.Errorf("deposit request %d was not approved", id)
+	}
+	if request.ReversalStatus == REVERSAL_PENDING || request.ReversalStatus == REVERSAL_APPROVED {
+		return Message{}, fmt.Errorf("deposit request %d is reversed", id)
+	}
+	var completions int
+	err = s.db.Get(&completions, "SELECT COUNT(*) FROM atm_message WHERE authorization_id=$1 AND parent_id=0", id)
+	if err != nil {
+		return Message{}, err
+	}
+	if completions > 0 {
+		return Message{}, fmt.Errorf("deposit request %d is already completed", id)
+	}
+	if completion.CountedAmount <= 0 {
+		return Message{}, fmt.Errorf("counted amount is required, reverse the request when nothing was deposited")
+	}
+	message := request
+	message.TransactionAmount = completion.CountedAmount
+	message.BillingAmount = 0
+	message.DepositItems = completion.Items
+	message.AuthorizationId = request.Id
+	message.ResponseCode = ""
+	message.ReversalStatus = ""
+	message.ReversalResponseCode = ""
+	message.OriginalDataElements = ""
+	return message, nil
+}
+
+// SendDepositCompletion queues the completion advice (0220).
+func (a *App) SendDepositCompletion(id int, completion DepositCompletion) (SafItem, error) {
+	message, err := a.messageService.newDepositCompletion(id, completion)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return SafItem{}, err
+	}
+	err = validateMessage(message)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return SafItem{}, err
+	}
+	err = buildAdvice(&message, false)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return SafItem{}, err
+	}
+	item, err := a.safQueue.enqueue(message)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return SafItem{}, err
+	}
+	return item, nil
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+type DialerKind string
+
+const (
+	DIRECT_DIALER DialerKind = "direct"
+	SSH_DIALER    DialerKind = "ssh"
+	SOCKS5_DIALER DialerKind = "socks5"
+	HTTP_DIALER   DialerKind = "http"
+)
+
+// dialer connects to the switches and the first jump host.
+type dialer interface {
+	dial(address string, timeout time.Duration) (net.Conn, error)
+}
+
+type directDialer struct{}
+
+// sshDialer opens direct-tcpip channels through a running tunnel.
+type sshDialer struct {
+	tunnels *tunnelManager
+	tunnel  string
+}
+
+type socks5Dialer struct {
+	proxy    string
+	username string
+	password string
+}
+
+type httpDialer struct {
+	proxy    string
+	username string
+	password string
+}
+
+// newDialer builds the DIALER of the active profile. Jump hosts are dialed
+// without tunnels, directly instead of through SSH.
+func newDialer(secrets *secretService, tunnels *tunnelManager) (dialer, error) {
+	kind := DialerKind(viper.GetString("DIALER"))
+	switch kind {
+	case DIRECT_DIALER, "":
+		return directDialer{}, nil
+	case SSH_DIALER:
+		if tunnels == nil {
+			return directDialer{}, nil
+		}
+		return sshDialer{tunnels: tunnels, tunnel: viper.GetString("DIALER_TUNNEL")}, nil
+	case SOCKS5_DIALER, HTTP_DIALER:
+		proxy := viper.GetString("PROXY_ADDRESS")
+		if len(proxy) == 0 {
+			return nil, errors.New("missing PROXY_ADDRESS, please configure it in the settings")
+		}
+		password, err := secrets.get("PROXY_PASSWORD")
+		if err != nil {
+			return nil, err
+		}
+		username := viper.GetString("PROXY_USERNAME")
+		if kind == SOCKS5_DIALER {
+			return socks5Dialer{proxy: proxy, username: username, password: password}, nil
+		}
+		return httpDialer{proxy: proxy, username: username, password: password}, nil
+	default:
+		return nil, fmt.Errorf("unknown DIALER %q", kind)
+	}
+}
+
+func (directDialer) dial(address string, timeout time.Duration) (net.Conn, error) {
+	return net.DialTimeout("tcp", address, timeout)
+}
+
+func (d sshDialer) dial(address string, timeout time.Duration) (net.Conn, error) {
+	client, err := d.tunnels.client(d.tunnel)
+	if err != nil {
+		return nil, err
+	}
+	type result struct {
+		conn net.Conn
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		conn, err := client.Dial("tcp", address)
+		done <- result{conn, err}
+	}()
+	select {
+	case r := <-done:
+		return r.conn, r.err
+	case <-time.After(timeout):
+		go func() {
+			if r := <-done; r.conn != nil {
+				r.conn.Close()
+			}
+		}()
+		return nil, fmt.Errorf("dial %s through tunnel timed out", address)
+	}
+}
+
+// dial connects through a SOCKS5 proxy (RFC 1928 and 1929).
+func (d socks5Dialer) dial(address string, timeout time.Duration) (net.Conn, error) {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port in %s", address)
+	}
+	if len(host) > 255 {
+		return nil, fmt.Errorf("host name %s is too long", host)
+	}
+	conn, err := net.DialTimeout("tcp", d.proxy, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SOCKS5 proxy %s: %w", d.proxy, err)
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+	err = d.handshake(conn, host, port)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("SOCKS5 proxy %s: %w", d.proxy, err)
+	}
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+func (d socks5Dialer) handshake(conn net.Conn, host string, port int) error {
+	method := byte(0x00)
+	if len(d.username) > 0 {
+		method = 0x02
+	}
+	_, err := conn.Write([]byte{0x05, 0x01, method})
+	if err != nil {
+		return err
+	}
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+	if reply[0] != 0x05 || reply[1] != method {
+		return errors.New("no acceptable authentication method")
+	}
+	if method == 0x02 {
+		if len(d.username) > 255 || len(d.password) > 255 {
+			return errors.New("proxy credentials are too long")
+		}
+		auth := []byte{0x01, byte(len(d.username))}
+		auth = append(auth, d.username...)
+		auth = append(auth, byte(len(d.password)))
+		auth = append(auth, d.password...)
+		if _, err := conn.Write(auth); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(conn, reply); err != nil {
+			return err
+		}
+		if reply[1] != 0x00 {
+			return errors.New("authentication failed")
+		}
+	}
+
+	request := []byte{0x05, 0x01, 0x00}
+	if ip := net.ParseIP(host); ip != nil && ip.To4() != nil {
+		request = append(request, 0x01)
+		request = append(request, ip.To4()...)
+	} else if ip != nil {
+		request = append(request, 0x04)
+		request = append(request, ip.To16()...)
+	} else {
+		request = append(request, 0x03, byte(len(host)))
+		request = append(request, host...)
+	}
+	request = append(request, byte(port>>8), byte(port))
+	if _, err := conn.Write(request); err != nil {
+		return err
+	}
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+	if header[1] != 0x00 {
+		return fmt.Errorf("connect failed with reply code %d", header[1])
+	}
+	var skip int
+	switch header[3] {
+	case 0x01:
+		skip = net.IPv4len
+	case 0x04:
+		skip = net.IPv6len
+	case 0x03:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return err
+		}
+		skip = int(length[0])
+	default:
+		return fmt.Errorf("unknown address type %d", header[3])
+	}
+	_, err = io.ReadFull(conn, make([]byte, skip+2))
+	return err
+}
+
+// dial opens a tunnel with the HTTP CONNECT method.
+func (d httpDialer) dial(address string, timeout time.Duration) (net.Conn, error) {
+	conn, err := net.DialTimeout("tcp", d.proxy, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to HTTP proxy %s: %w", d.proxy, err)
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+	err = d.connect(conn, address)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("HTTP proxy %s: %w", d.proxy, err)
+	}
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+func (d httpDialer) connect(conn net.Conn, address string) error {
+	request := fmt.Sprintf("CONNECT %s HTTP/1.1\r\nHost: %s\r\n", address, address)
+	if len(d.username) > 0 {
+		credentials := base64.StdEncoding.EncodeToString([]byte(d.username + ":" + d.password))
+		request += "Proxy-Authorization: Basic " + credentials + "\r\n"
+	}
+	_, err := io.WriteString(conn, request+"\r\n")
+	if err != nil {
+		return err
+	}
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, &http.Request{Method: http.MethodConnect})
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("CONNECT to %s refused: %s", address, response.Status)
+	}
+	if reader.Buffered() > 0 {
+		return errors.New("data was sent before the tunnel was established")
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+)
+
+type proxyStep struct {
+	want  []byte
+	reply []byte
+}
+
+// fakeProxy checks each request written by the client and answers it.
+func fakeProxy(t *testing.T, conn net.Conn, steps []proxyStep) {
+	defer conn.Close()
+	for _, step := range steps {
+		got := make([]byte, len(step.want))
+		if _, err := io.ReadFull(conn, got); err != nil {
+			t.Errorf("proxy read: %v", err)
+			return
+		}
+		if !bytes.Equal(got, step.want) {
+			t.Errorf("proxy got % x, want % x", got, step.want)
+			return
+		}
+		if _, err := conn.Write(step.reply); err != nil {
+			t.Errorf("proxy write: %v", err)
+			return
+		}
+	}
+}
+
+func TestSocks5Handshake(t *testing.T) {
+	connect := func(address ...byte) []byte {
+		return append([]byte{0x05, 0x01, 0x00}, address...)
+	}
+	bound := []byte{0x05, 0x00, 0x00, 0x01, 127, 0, 0, 1, 0x1F, 0x90}
+	tests := []struct {
+		name    string
+		dialer  socks5Dialer
+		host    string
+		steps   []proxyStep
+		wantErr bool
+	}{
+		{
+			name: "no authentication",
+			host: "atm.example.com",
+			steps: []proxyStep{
+				{[]byte{0x05, 0x01, 0x00}, []byte{0x05, 0x00}},
+				{connect(append(append([]byte{0x03, 15}, "atm.example.com"...), 0xC3, 0xCA)...), bound},
+			},
+		},
+		{
+			name:   "username and password",
+			dialer: socks5Dialer{username: "user", password: "pass"},
+			host:   "10.0.0.1",
+			steps: []proxyStep{
+				{[]byte{0x05, 0x01, 0x02}, []byte{0x05, 0x02}},
+				{append(append([]byte{0x01, 4}, "user\x04"...), "pass"...), []byte{0x01, 0x00}},
+				{connect(0x01, 10, 0, 0, 1, 0xC3, 0xCA), []byte{0x05, 0x00, 0x00, 0x03, 3, 'a', 't', 'm', 0x1F, 0x90}},
+			},
+		},
+		{
+			name: "IPv6",
+			host: "::1",
+			steps: []proxyStep{
+				{[]byte{0x05, 0x01, 0x00}, []byte{0x05, 0x00}},
+				{connect(0x04, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0xC3, 0xCA), bound},
+			},
+		},
+		{
+			name:   "authentication failure",
+			dialer: socks5Dialer{username: "user", password: "wrong"},
+			host:   "10.0.0.1",
+			steps: []proxyStep{
+				{[]byte{0x05, 0x01, 0x02}, []byte{0x05, 0x02}},
+				{append(append([]byte{0x01, 4}, "user\x05"...), "wrong"...), []byte{0x01, 0x01}},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "no acceptable method",
+			dialer:  socks5Dialer{username: "user"},
+			host:    "10.0.0.1",
+			steps:   []proxyStep{{[]byte{0x05, 0x01, 0x02}, []byte{0x05, 0xFF}}},
+			wantErr: true,
+		},
+		{
+			name: "connection refused",
+			host: "10.0.0.1",
+			steps: []proxyStep{
+				{[]byte{0x05, 0x01, 0x00}, []byte{0x05, 0x00}},
+				{connect(0x01, 10, 0, 0, 1, 0xC3, 0xCA), []byte{0x05, 0x05, 0x00, 0x01}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		client, server := net.Pipe()
+		go fakeProxy(t, server, tt.steps)
+		err := tt.dialer.handshake(client, tt.host, 50122)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: handshake() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		client.Close()
+	}
+}
+
+func TestHttpConnect(t *testing.T) {
+	tests := []struct {
+		name          string
+		dialer        httpDialer
+		reply         string
+		authorization string
+		wantErr       bool
+	}{
+		{name: "established", reply: "HTTP/1.1 200 Connection established\r\n\r\n"},
+		{
+			name:          "basic authentication",
+			dialer:        httpDialer{username: "user", password: "pass"},
+			reply:         "HTTP/1.1 200 OK\r\n\r\n",
+			authorization: "Basic dXNlcjpwYXNz",
+		},
+		{name: "proxy authentication required", reply: "HTTP/1.1 407 Proxy Authentication Required\r\nContent-Length: 0\r\n\r\n", wantErr: true},
+		{name: "forbidden", reply: "HTTP/1.1 403 Forbidden\r\nContent-Length: 0\r\n\r\n", wantErr: true},
+		{name: "data before the tunnel", reply: "HTTP/1.1 200 OK\r\n\r\n0800", wantErr: true},
+		{name: "not HTTP", reply: "SSH-2.0-OpenSSH_8.9\r\n\r\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		client, server := net.Pipe()
+		go func(authorization string, reply string) {
+			defer server.Close()
+			request, err := http.ReadRequest(bufio.NewReader(server))
+			if err != nil {
+				t.Errorf("proxy read: %v", err)
+				return
+			}
+			if request.Method != http.MethodConnect || request.Host != "atm.example.com:50122" {
+				t.Errorf("proxy got %s %s", request.Method, request.Host)
+			}
+			if got := request.Header.Get("Proxy-Authorization"); got != authorization {
+				t.Errorf("Proxy-Authorization = %q, want %q", got, authorization)
+			}
+			io.WriteString(server, reply)
+		}(tt.authorization, tt.reply)
+		err := tt.dialer.connect(client, "atm.example.com:50122")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: connect() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		client.Close()
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+const defaultTimeout = 30 * time.Second
+
+type endpoint struct {
+	addresses []string
+	timeout   time.Duration
+	tls       *tls.Config
+	framing   framing
+}
+
+// getEndpoint resolves the addresses of a switch from its <SWITCH>_HOST and
+// <SWITCH>_PORT settings, falling back to the global HOST and PORT.
+func getEndpoint(atmSwitch AtmSwitch) (endpoint, error) {
+	key := func(name string) string {
+		return fmt.Sprintf("%s_%s", atmSwitch, name)
+	}
+	host := viper.GetString(key("HOST"))
+	port := viper.GetString(key("PORT"))
+	if len(host) == 0 {
+		host = viper.GetString("HOST")
+	}
+	if len(port) == 0 {
+		port = viper.GetString("PORT")
+	}
+	addresses := []string{net.JoinHostPort(host, port)}
+
+	secondaryHost := viper.GetString(key("SECONDARY_HOST"))
+	secondaryPort := viper.GetString(key("SECONDARY_PORT"))
+	if len(secondaryHost) > 0 {
+		if len(secondaryPort) == 0 {
+			secondaryPort = port
+		}
+		addresses = append(addresses, net.JoinHostPort(secondaryHost, secondaryPort))
+	}
+
+	timeout := time.Duration(viper.GetInt(key("TIMEOUT"))) * time.Second
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	tlsConfig, err := getTlsConfig(atmSwitch)
+	if err != nil {
+		return endpoint{}, err
+	}
+	framing, err := getFraming(atmSwitch)
+	if err != nil {
+		return endpoint{}, err
+	}
+	return endpoint{addresses: addresses, timeout: timeout, tls: tlsConfig, framing: framing}, nil
+}
+
+// dial tries the primary address before the secondary.
+func (e endpoint) dial(d dialer) (net.Conn, error) {
+	var err error
+	for _, address := range e.addresses {
+		var conn net.Conn
+		conn, err = d.dial(address, e.timeout)
+		if err == nil && e.tls != nil {
+			conn, err = e.handshake(conn, address)
+		}
+		if err == nil {
+			log.Printf("connected to %s", address)
+			return conn, nil
+		}
+		log.Error().Err(err).Msgf("failed to connect to %s", address)
+	}
+	return nil, fmt.Errorf("failed to connect to %s: %w", strings.Join(e.addresses, ", "), err)
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+type Fault string
+
+const (
+	CORRUPT_LENGTH     Fault = "CORRUPT_LENGTH"
+	TRUNCATE_FRAME     Fault = "TRUNCATE_FRAME"
+	FLIP_BITMAP        Fault = "FLIP_BITMAP"
+	OVERLENGTH_LLVAR   Fault = "OVERLENGTH_LLVAR"
+	WRONG_MTI          Fault = "WRONG_MTI"
+	DUPLICATE_STAN     Fault = "DUPLICATE_STAN"
+	DROP_CORTEX_HEADER Fault = "DROP_CORTEX_HEADER"
+)
+
+type FaultCase struct {
+	Fault       Fault       `json:"fault"`
+	Description string      `json:"description"`
+	Switches    []AtmSwitch `json:"switches"`
+}
+
+var faultCases = []FaultCase{
+	{CORRUPT_LENGTH, "Length header is filled with 0xFF bytes instead of the real frame length", []AtmSwitch{CORTEX, NARADA, POSTBRIDGE}},
+	{TRUNCATE_FRAME, "Frame body is cut in half and the length header matches the truncated body", []AtmSwitch{CORTEX, NARADA, POSTBRIDGE}},
+	{FLIP_BITMAP, "Bit for field 5 is flipped in the primary bitmap so the host expects a field that is not there", []AtmSwitch{CORTEX, NARADA}},
+	{OVERLENGTH_LLVAR, "Primary account number is sent as a 25 digit LLVAR, above the ISO 8583 maximum of 19", []AtmSwitch{CORTEX, NARADA, POSTBRIDGE}},
+	{WRONG_MTI, "Request is sent with the response MTI (e.g. 0210 instead of 0200)", []AtmSwitch{CORTEX, NARADA, POSTBRIDGE}},
+	{DUPLICATE_STAN, "Trace number and RRN are copied from the last message sent to the same switch", []AtmSwitch{CORTEX, NARADA, POSTBRIDGE}},
+	{DROP_CORTEX_HEADER, "ISO8583-1993 header is removed from the Cortex frame", []AtmSwitch{CORTEX}},
+}
+
+const bitmapFieldFive = 0x08
+
+func getFaultCase(fault Fault) (FaultCase, error) {
+	for _, c := range faultCases {
+		if c.Fault == fault {
+			return c, nil
+		}
+	}
+	return FaultCase{}, fmt.Errorf("unknown fault: %s", fault)
+}
+
+func (c FaultCase) supports(atmSwitch AtmSwitch) bool {
+	for _, s := range c.Switches {
+		if s == atmSwitch {
+			return true
+		}
+	}
+	return false
+}
+
+// injectMessageFault alters a built message before it is saved and packed.
+func (s *messageService) injectMessageFault(message *Message, fault Fault) error {
+	switch fault {
+	case OVERLENGTH_LLVAR:
+		if len(message.PrimaryAccountNumber) == 0 {
+			return errors.New("fault requires a primary account number")
+		}
+		message.PrimaryAccountNumber = (message.PrimaryAccountNumber + strings.Repeat("9", 25))[:25]
+	case WRONG_MTI:
+		if len(message.Mti) != 4 {
+			return fmt.Errorf("fault requires a 4 digit MTI, got %q", message.Mti)
+		}
+		mti, err := responseMti(message.Mti)
+		if err != nil {
+			// not a request, swap the request and response function digit
+			mti = message.Mti[:2] + string(message.Mti[2]^1) + message.Mti[3:]
+		}
+		message.Mti = mti
+	case DUPLICATE_STAN:
+		last, err := s.getLastMessage(message.Switch)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("no previous %s message to duplicate", message.Switch)
+		}
+		if err != nil {
+			return err
+		}
+		message.TraceNumber = last.TraceNumber
+		message.Rrn = last.Rrn
+	}
+	return nil
+}
+
+// injectFrameFault alters a packed message while framing it.
+func injectFrameFault(atmSwitch AtmSwitch, f framing, body []byte, fault Fault) ([]byte, error) {
+	if len(body) == 0 {
+		return nil, errors.New("frame is too short to inject a fault")
+	}
+	switch fault {
+	case CORRUPT_LENGTH:
+		frame, err := f.frame(body)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < f.headerSize(); i++ {
+			frame[i] = 0xFF
+		}
+		return frame, nil
+	case TRUNCATE_FRAME:
+		return f.frame(body[:len(body)/2])
+	case FLIP_BITMAP:
+		offset, err := bitmapOffset(atmSwitch)
+		if err != nil {
+			return nil, err
+		}
+		if len(body) <= offset {
+			return nil, errors.New("frame has no bitmap")
+		}
+		faulty := make([]byte, len(body))
+		copy(faulty, body)
+		faulty[offset] ^= bitmapFieldFive
+		return f.frame(faulty)
+	case DROP_CORTEX_HEADER:
+		if len(body) <= len(header) {
+			return nil, errors.New("frame has no Cortex header")
+		}
+		return f.frame(body[len(header):])
+	}
+	return f.frame(body)
+}
+
+func bitmapOffset(atmSwitch AtmSwitch) (int, error) {
+	switch atmSwitch {
+	case CORTEX:
+		// ASCII header followed by a 2 byte BCD MTI
+		return len(header) + 2, nil
+	case NARADA:
+		// 4 byte EBCDIC MTI
+		return 4, nil
+	}
+	return 0, fmt.Errorf("%s has no binary bitmap", atmSwitch)
+}
+
+func (a *App) GetFaultCases() []FaultCase {
+	return faultCases
+}
+
+func (a *App) SendFaultMessage(message Message, fault Fault) (AtmResponse, error) {
+	faultCase, err := getFaultCase(fault)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return AtmResponse{}, err
+	}
+	if !faultCase.supports(message.Switch) {
+		err := fmt.Errorf("fault %s is not supported for %s", fault, message.Switch)
+		log.Error().Err(err).Msg("")
+		return AtmResponse{}, err
+	}
+	atmSwitch, err := getAtmSwitch(message)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return AtmResponse{}, err
+	}
+	err = validateMessage(message)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return AtmResponse{}, err
+	}
+	err = atmSwitch.build(&message, false)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return AtmResponse{}, err
+	}
+	err = a.messageService.injectMessageFault(&message, fault)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return AtmResponse{}, err
+	}
+	_, err = a.messageService.saveMessage(message)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return AtmResponse{}, err
+	}
+	b, err := atmSwitch.pack(message)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return AtmResponse{}, err
+	}
+	endpoint, err := getEndpoint(message.Switch)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return AtmResponse{}, err
+	}
+	b, err = injectFrameFault(message.Switch, endpoint.framing, b, fault)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return AtmResponse{}, err
+	}
+	log.Printf("sending %s fault: % x", fault, b)
+	// faulty frames would desynchronize a persistent link
+	response, err := exchangeOnce(a, endpoint, b)
+	if err != nil {
+		return AtmResponse{}, err
+	}
+	return atmSwitch.unpack(message, response)
+}
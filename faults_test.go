@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestInjectMessageFault(t *testing.T) {
+	s := &messageService{db: newTestDB(t)}
+	tests := []struct {
+		mti     string
+		want    string
+		wantErr bool
+	}{
+		{mti: "0200", want: "0210"},
+		{mti: "1200", want: "1210"},
+		{mti: "0421", want: "0430"},
+		{mti: "0210", want: "0200"},
+		{mti: "020X", want: "021X"},
+		{mti: "02", wantErr: true},
+		{mti: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		message := Message{Mti: tt.mti}
+		err := s.injectMessageFault(&message, WRONG_MTI)
+		if (err != nil) != tt.wantErr || err == nil && message.Mti != tt.want {
+			t.Errorf("WRONG_MTI on %q = %q, %v, want %q", tt.mti, message.Mti, err, tt.want)
+		}
+	}
+
+	message := Message{PrimaryAccountNumber: "4111111111111111"}
+	if err := s.injectMessageFault(&message, OVERLENGTH_LLVAR); err != nil || message.PrimaryAccountNumber != "4111111111111111999999999" {
+		t.Errorf("OVERLENGTH_LLVAR = %q, %v", message.PrimaryAccountNumber, err)
+	}
+	if err := s.injectMessageFault(&Message{}, OVERLENGTH_LLVAR); err == nil {
+		t.Error("OVERLENGTH_LLVAR without a primary account number should fail")
+	}
+
+	message = newTestMessage()
+	if err := s.injectMessageFault(&message, DUPLICATE_STAN); err == nil {
+		t.Error("DUPLICATE_STAN without a previous message should fail")
+	}
+	last := newTestMessage()
+	last.TraceNumber, last.Rrn = "000001", "000000000001"
+	_, err := s.saveMessage(last)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.injectMessageFault(&message, DUPLICATE_STAN); err != nil || message.TraceNumber != "000001" || message.Rrn != "000000000001" {
+		t.Errorf("DUPLICATE_STAN = %s %s, %v", message.TraceNumber, message.Rrn, err)
+	}
+}
+
+func TestInjectFrameFault(t *testing.T) {
+	body, err := cortex.pack(newTestMessage())
+	if err != nil {
+		t.Fatal(err)
+	}
+	flipped := append([]byte{}, body...)
+	flipped[len(header)+2] ^= bitmapFieldFive
+	want := map[Fault][]byte{
+		TRUNCATE_FRAME:     body[:len(body)/2],
+		FLIP_BITMAP:        flipped,
+		OVERLENGTH_LLVAR:   body,
+		WRONG_MTI:          body,
+		DUPLICATE_STAN:     body,
+		DROP_CORTEX_HEADER: body[len(header):],
+	}
+	for _, f := range []framing{
+		{kind: BINARY2_FRAMING},
+		{kind: ASCII4_FRAMING},
+		{kind: BCD2_FRAMING, tpdu: []byte{0x60, 0x00, 0x01, 0x00, 0x00}},
+	} {
+		for _, c := range faultCases {
+			frame, err := injectFrameFault(CORTEX, f, body, c.Fault)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := f.read(bytes.NewReader(frame))
+			if c.Fault == CORRUPT_LENGTH {
+				if err == nil || !bytes.Equal(frame[:f.headerSize()], bytes.Repeat([]byte{0xFF}, f.headerSize())) {
+					t.Errorf("%s %s = % x, want an unreadable length", f.kind, c.Fault, frame[:f.headerSize()])
+				}
+				continue
+			}
+			if err != nil || !bytes.Equal(got, want[c.Fault]) {
+				t.Errorf("%s %s = % x, %v, want % x", f.kind, c.Fault, got, err, want[c.Fault])
+			}
+		}
+	}
+
+	if _, err := injectFrameFault(POSTBRIDGE, framing{kind: BINARY2_FRAMING}, []byte("<Iso8583PostXml/>"), FLIP_BITMAP); err == nil {
+		t.Error("FLIP_BITMAP on Postbridge should fail")
+	}
+	if _, err := injectFrameFault(CORTEX, framing{kind: BINARY2_FRAMING}, []byte(header), DROP_CORTEX_HEADER); err == nil {
+		t.Error("DROP_CORTEX_HEADER on a frame without a body should fail")
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/ssh"
+)
+
+var lastConnectionId uint64
+
+type TunnelMetrics struct {
+	Active       int64 `json:"active"`
+	Total        int64 `json:"total"`
+	BytesIn      int64 `json:"bytesIn"`
+	BytesOut     int64 `json:"bytesOut"`
+	DialFailures int64 `json:"dialFailures"`
+}
+
+// tunnelMetrics outlives the sessions of a tunnel and is updated atomically.
+type tunnelMetrics struct {
+	active       int64
+	total        int64
+	bytesIn      int64
+	bytesOut     int64
+	dialFailures int64
+}
+
+func (m *tunnelMetrics) snapshot() TunnelMetrics {
+	return TunnelMetrics{
+		Active:       atomic.LoadInt64(&m.active),
+		Total:        atomic.LoadInt64(&m.total),
+		BytesIn:      atomic.LoadInt64(&m.bytesIn),
+		BytesOut:     atomic.LoadInt64(&m.bytesOut),
+		DialFailures: atomic.LoadInt64(&m.dialFailures),
+	}
+}
+
+type countingWriter struct {
+	w     io.Writer
+	count *int64
+}
+
+func (c countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	atomic.AddInt64(c.count, int64(n))
+	return n, err
+}
+
+// forward proxies a local connection to targetAddr, half-closing each
+// direction when its source is done.
+func (s *tunnelSession) forward(name string, metrics *tunnelMetrics, client *ssh.Client, localConn net.Conn, targetAddr string) {
+	id := atomic.AddUint64(&lastConnectionId, 1)
+	targetConn, err := client.Dial("tcp", targetAddr)
+	if err != nil {
+		atomic.AddInt64(&metrics.dialFailures, 1)
+		log.Error().Err(err).Msgf("tunnel %s connection %d failed to connect to %s", name, id, targetAddr)
+		localConn.Close()
+		return
+	}
+	if !s.track(id, localConn, targetConn) {
+		localConn.Close()
+		targetConn.Close()
+		return
+	}
+	atomic.AddInt64(&metrics.active, 1)
+	atomic.AddInt64(&metrics.total, 1)
+	log.Printf("tunnel %s connection %d opened: %s -> %s", name, id, localConn.RemoteAddr(), targetAddr)
+
+	var closeOnce sync.Once
+	closeBoth := func() {
+		closeOnce.Do(func() {
+			localConn.Close()
+			targetConn.Close()
+		})
+	}
+	var out, in int64
+	var wg sync.WaitGroup
+	wg.Add(2)
+	pipe := func(dst net.Conn, src net.Conn, count *int64, total *int64) {
+		defer wg.Done()
+		n, err := io.Copy(countingWriter{dst, total}, src)
+		*count = n
+		if err != nil {
+			closeBoth()
+			return
+		}
+		closeWrite(dst)
+	}
+	go pipe(targetConn, localConn, &out, &metrics.bytesOut)
+	go pipe(localConn, targetConn, &in, &metrics.bytesIn)
+	wg.Wait()
+	closeBoth()
+
+	s.untrack(id)
+	atomic.AddInt64(&metrics.active, -1)
+	log.Printf("tunnel %s connection %d closed: %d bytes out, %d bytes in", name, id, out, in)
+}
+
+// closeWrite signals EOF to the peer while still reading from it.
+func closeWrite(conn net.Conn) {
+	if c, ok := conn.(interface{ CloseWrite() error }); ok {
+		c.CloseWrite()
+		return
+	}
+	conn.Close()
+}
+
+// track returns false if the session is already closed.
+func (s *tunnelSession) track(id uint64, conns ...net.Conn) bool {
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+	if s.conns == nil {
+		return false
+	}
+	s.conns[id] = conns
+	return true
+}
+
+func (s *tunnelSession) untrack(id uint64) {
+	s.connsMu.Lock()
+	delete(s.conns, id)
+	s.connsMu.Unlock()
+}
+
+func (s *tunnelSession) closeConns() {
+	s.connsMu.Lock()
+	conns := s.conns
+	s.conns = nil
+	s.connsMu.Unlock()
+	for _, c := range conns {
+		for _, conn := range c {
+			conn.Close()
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/spf13/viper"
+)
+
+type Framing string
+
+const (
+	BINARY2_FRAMING Framing = "binary2"
+	ASCII4_FRAMING  Framing = "ascii4"
+	BCD2_FRAMING    Framing = "bcd2"
+)
+
+const tpduSize = 5
+
+// framing is a length header and an optional TPDU before the message.
+type framing struct {
+	kind      Framing
+	inclusive bool
+	tpdu      []byte
+}
+
+// getFraming defaults to a 2 byte binary length without TPDU.
+func getFraming(atmSwitch AtmSwitch) (framing, error) {
+	key := func(name string) string {
+		return fmt.Sprintf("%s_%s", atmSwitch, name)
+	}
+	f := framing{
+		kind:      Framing(viper.GetString(key("FRAMING"))),
+		inclusive: viper.GetBool(key("FRAMING_INCLUSIVE")),
+	}
+	switch f.kind {
+	case "":
+		f.kind = BINARY2_FRAMING
+	case BINARY2_FRAMING, ASCII4_FRAMING, BCD2_FRAMING:
+	default:
+		return framing{}, fmt.Errorf("invalid %s %q, expected binary2, ascii4 or bcd2", key("FRAMING"), f.kind)
+	}
+	if tpdu := viper.GetString(key("TPDU")); len(tpdu) > 0 {
+		b, err := hex.DecodeString(tpdu)
+		if err != nil || len(b) != tpduSize {
+			return framing{}, fmt.Errorf("invalid %s %q, expected %d bytes in hex such as 6000010000", key("TPDU"), tpdu, tpduSize)
+		}
+		f.tpdu = b
+	}
+	return f, nil
+}
+
+func (f framing) headerSize() int {
+	if f.kind == ASCII4_FRAMING {
+		return 4
+	}
+	return 2
+}
+
+func (f framing) maxLength() int {
+	if f.kind == BINARY2_FRAMING {
+		return 0xFFFF
+	}
+	return 9999
+}
+
+func (f framing) frame(message []byte) ([]byte, error) {
+	length := len(f.tpdu) + len(message)
+	if f.inclusive {
+		length += f.headerSize()
+	}
+	if length > f.maxLength() {
+		return nil, fmt.Errorf("frame of %d bytes exceeds the %s maximum of %d", length, f.kind, f.maxLength())
+	}
+	var frame []byte
+	switch f.kind {
+	case ASCII4_FRAMING:
+		frame = []byte(fmt.Sprintf("%04d", length))
+	case BCD2_FRAMING:
+		frame = []byte{byte(length/1000)<<4 | byte(length/100%10), byte(length/10%10)<<4 | byte(length%10)}
+	default:
+		frame = []byte{byte(length >> 8), byte(length)}
+	}
+	frame = append(frame, f.tpdu...)
+	return append(frame, message...), nil
+}
+
+// read returns the message of one frame.
+func (f framing) read(r io.Reader) ([]byte, error) {
+	header := make([]byte, f.headerSize())
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return nil, err
+	}
+	var length int
+	switch f.kind {
+	case ASCII4_FRAMING:
+		length, err = strconv.Atoi(string(header))
+		if err != nil {
+			return nil, fmt.Errorf("invalid ASCII length header %q", header)
+		}
+	case BCD2_FRAMING:
+		for _, b := range header {
+			if b>>4 > 9 || b&0x0F > 9 {
+				return nil, fmt.Errorf("invalid BCD length header % x", header)
+			}
+			length = length*100 + int(b>>4)*10 + int(b&0x0F)
+		}
+	default:
+		length = int(header[0])<<8 | int(header[1])
+	}
+	if f.inclusive {
+		length -= f.headerSize()
+	}
+	if length < len(f.tpdu) {
+		return nil, fmt.Errorf("frame length %d is shorter than the TPDU", length)
+	}
+	frame := make([]byte, length)
+	_, err = io.ReadFull(r, frame)
+	if err != nil {
+		return nil, err
+	}
+	return frame[len(f.tpdu):], nil
+}
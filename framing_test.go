@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFramingFrame(t *testing.T) {
+	tests := []struct {
+		framing framing
+		want    string
+	}{
+		{framing{kind: BINARY2_FRAMING}, "\x00\x040800"},
+		{framing{kind: BINARY2_FRAMING, inclusive: true}, "\x00\x060800"},
+		{framing{kind: ASCII4_FRAMING}, "00040800"},
+		{framing{kind: ASCII4_FRAMING, inclusive: true}, "00080800"},
+		{framing{kind: BCD2_FRAMING, tpdu: []byte{0x60, 0x00, 0x01, 0x00, 0x00}}, "\x00\x09\x60\x00\x01\x00\x000800"},
+	}
+	for _, tt := range tests {
+		got, err := tt.framing.frame([]byte("0800"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, []byte(tt.want)) {
+			t.Errorf("%s frame() = % x, want % x", tt.framing.kind, got, tt.want)
+		}
+	}
+	_, err := framing{kind: ASCII4_FRAMING}.frame(make([]byte, 10000))
+	if err == nil {
+		t.Error("frame() of 10000 bytes with ascii4 should fail")
+	}
+}
+
+func TestFramingRead(t *testing.T) {
+	message := []byte("08108220000002000000040000000000000010191200001234")
+	for _, f := range []framing{
+		{kind: BINARY2_FRAMING},
+		{kind: ASCII4_FRAMING, inclusive: true},
+		{kind: BCD2_FRAMING, tpdu: []byte{0x60, 0x00, 0x01, 0x00, 0x00}},
+	} {
+		frame, err := f.frame(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := bytes.NewReader(append(frame, frame...))
+		got, err := f.read(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, message) || r.Len() != len(frame) {
+			t.Errorf("%s read() = %q with %d bytes left, want %q with %d", f.kind, got, r.Len(), message, len(frame))
+		}
+	}
+}
+
+func TestFramingReadInvalid(t *testing.T) {
+	tests := []struct {
+		framing framing
+		frame   string
+	}{
+		{framing{kind: ASCII4_FRAMING}, "00a40800"},
+		{framing{kind: BCD2_FRAMING}, "\x00\x1a0800"},
+		{framing{kind: BINARY2_FRAMING, tpdu: []byte{0x60, 0x00, 0x01, 0x00, 0x00}}, "\x00\x03\x60\x00\x01"},
+		{framing{kind: BINARY2_FRAMING}, "\x00\x080800"},
+	}
+	for _, tt := range tests {
+		_, err := tt.framing.read(bytes.NewReader([]byte(tt.frame)))
+		if err == nil {
+			t.Errorf("%s read(%q) should fail", tt.framing.kind, tt.frame)
+		}
+	}
+}
@@ -5,6 +5,11 @@ import Navigation from './navigation'
 import { Toaster } from './ui/toaster'
 import { History } from './history'
 import Config from './settings'
+import { Server } from './server'
+import { Recon } from './recon'
+import { Tunnels } from './tunnels'
+import HostKeyDialog from './host-key-dialog'
+import SshChallengeDialog from './ssh-challenge-dialog'
 
 const Main = () => {
 
@@ -17,6 +22,11 @@ const Main = () => {
         {page === 'home' && <AtmForm key={key}/>}
         {page === 'history' && <History />}
         {page === 'settings' && <Config />}
+        {page === 'server' && <Server />}
+        {page === 'recon' && <Recon />}
+        {page === 'tunnels' && <Tunnels />}
+        <HostKeyDialog />
+        <SshChallengeDialog />
         <Toaster />
       </div>
     </div>
@@ -18,12 +18,12 @@ import {
 } from '@/components/ui/select'
 import * as z from 'zod'
 import { zodResolver } from '@hookform/resolvers/zod'
-import { AtmSwitch, Bank, Channel, Currency, Device, Transaction } from '@/lib/message'
+import { AtmSwitch, Bank, Channel, Device, Transaction } from '@/lib/message'
 import { useForm } from 'react-hook-form'
 import { enumFromStringValue, getEnumKeys } from '@/lib/helper'
 import { Form, FormControl, FormField, FormItem, FormLabel, FormMessage } from './ui/form'
-import { useState } from 'react'
-import { SendFinancialMessage } from '../../wailsjs/go/main/App'
+import { useEffect, useState } from 'react'
+import { GetCurrencies, GetFaultCases, SendAdviceMessage, SendFaultMessage, SendFinancialMessage, ValidateMessage } from '../../wailsjs/go/main/App'
 import { main } from '../../wailsjs/go/models'
 import AtmResponseDialog from './atm-response-dialog'
 import { useRecoilState } from 'recoil'
@@ -50,14 +50,23 @@ export function AtmForm () {
     acquiringInstitutionCode: z.string({}).min(1, 'Acquiring Institution Code is required.').max(11),
     receivingInstitutionCode: z.string({}).max(11).optional(),
     transactionFee: z.coerce.number().optional(),
+    depositItems: z.coerce.number().int().min(0).max(999).optional(),
+    chequeNumber: z.string().max(20).optional(),
+    pin: z.string().max(12).optional(),
+    newPin: z.string().max(12).optional(),
+    referenceCode: z.string().max(20).optional(),
+    mobileNumber: z.string().max(15).optional(),
+    otp: z.string().max(8).optional(),
     terminalNameAndLocation: z.string({}).max(99),
-    currencyCode: z.nativeEnum(Currency, {
-      invalid_type_error: `Invalid currency, should be any of ${getEnumKeys(Currency)}.`,
+    currencyCode: z.string({
       required_error: 'Currency is required.'
-    }),
+    }).length(3, 'Currency is required.'),
+    billingAmount: z.coerce.number().optional(),
+    billingCurrencyCode: z.string().optional(),
+    conversionRate: z.coerce.number().optional(),
     terminalId: z.string({}).min(8, 'Terminal ID must contain 8 characters.').max(8),
-    sourceAccount: z.string().max(28).optional(),
-    destinationAccount: z.string().max(28).optional(),
+    sourceAccount: z.string().max(99).optional(),
+    destinationAccount: z.string().max(99).optional(),
     channel: z.nativeEnum(Channel, {
       invalid_type_error: `Invalid channel, should be any of ${getEnumKeys(Channel)}.`,
       required_error: 'Channel is required.'
@@ -85,9 +94,19 @@ export function AtmForm () {
       primaryAccountNumber: message.primaryAccountNumber ?? '',
       transactionAmount: message.transactionAmount ?? 0,
       transactionFee: message.transactionFee ?? 0,
+      depositItems: message.depositItems ?? 0,
+      chequeNumber: message.chequeNumber ?? '',
+      pin: '',
+      newPin: '',
+      referenceCode: message.referenceCode ?? '',
+      mobileNumber: message.mobileNumber ?? '',
+      otp: '',
       acquiringInstitutionCode: message.acquiringInstitutionCode ?? '',
       receivingInstitutionCode: message.receivingInstitutionCode ?? '',
-      currencyCode: enumFromStringValue(Currency, message.currencyCode),
+      currencyCode: message.currencyCode ?? '',
+      billingAmount: message.billingAmount ?? 0,
+      billingCurrencyCode: message.billingCurrencyCode ?? '',
+      conversionRate: message.conversionRate ?? 0,
       destinationAccount: message.destinationAccount ?? '',
       sourceAccount: message.sourceAccount ?? '',
       targetBank: enumFromStringValue(Bank, message.targetBank ?? ''),
@@ -98,6 +117,19 @@ export function AtmForm () {
 
   const [, setLoading] = useRecoilState(loadingState)
 
+  const [currencies, setCurrencies] = useState<main.CurrencyInfo[]>([])
+  useEffect(() => {
+    GetCurrencies().then(setCurrencies)
+  }, [])
+
+  const [faultCases, setFaultCases] = useState<main.FaultCase[]>([])
+  const [fault, setFault] = useState('')
+  useEffect(() => {
+    GetFaultCases().then(setFaultCases)
+  }, [])
+  const selectedSwitch = form.watch('switch')
+  const switchFaults = faultCases.filter(c => c.switches.includes(selectedSwitch))
+
   const [atmResponse, setAtmResponse] = useState<main.AtmResponse>()
   const [isOpen, setOpen] = useState(false)
   const { toast } = useToast()
@@ -105,6 +137,11 @@ export function AtmForm () {
     let response
     try {
       setLoading(true)
+      const errors = await ValidateMessage(data as main.Message)
+      if (errors && errors.length > 0) {
+        errors.forEach(e => form.setError(e.field as keyof z.infer<typeof formSchema>, { message: e.message }))
+        return
+      }
       response = await SendFinancialMessage(data)
       setAtmResponse(response)
       setOpen(true)
@@ -117,6 +154,47 @@ export function AtmForm () {
     }
   }
 
+  const onAdvice = async (data: z.infer<typeof formSchema>) => {
+    try {
+      setLoading(true)
+      const errors = await ValidateMessage(data as main.Message)
+      if (errors && errors.length > 0) {
+        errors.forEach(e => form.setError(e.field as keyof z.infer<typeof formSchema>, { message: e.message }))
+        return
+      }
+      const item = await SendAdviceMessage(data as main.Message)
+      toast({
+        description: `Advice ${item.mti} queued as SAF item ${item.id}.`,
+      })
+    }catch(error: any) {
+      toast({
+        description: error,
+      })
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  const onFault = async (data: z.infer<typeof formSchema>) => {
+    try {
+      setLoading(true)
+      const errors = await ValidateMessage(data as main.Message)
+      if (errors && errors.length > 0) {
+        errors.forEach(e => form.setError(e.field as keyof z.infer<typeof formSchema>, { message: e.message }))
+        return
+      }
+      const response = await SendFaultMessage(data as main.Message, fault)
+      setAtmResponse(response)
+      setOpen(true)
+    }catch(error: any) {
+      toast({
+        description: error,
+      })
+    } finally {
+      setLoading(false)
+    }
+  }
+
   return (
     <>
       <AtmResponseDialog isOpen={isOpen} setOpen={setOpen} atmResponse={atmResponse}/>
@@ -149,6 +227,12 @@ export function AtmForm () {
                               <SelectItem value="ELOAD">E-Load</SelectItem>
                               <SelectItem value="BILLS">Bills Payment</SelectItem>
                               <SelectItem value="PURCHASE">Purchase</SelectItem>
+                              <SelectItem value="PRE_AUTH">Pre-Auth</SelectItem>
+                              <SelectItem value="DEPOSIT">Cash Deposit</SelectItem>
+                              <SelectItem value="CHEQUE_DEPOSIT">Cheque Deposit</SelectItem>
+                              <SelectItem value="MINI_STATEMENT">Mini-Statement</SelectItem>
+                              <SelectItem value="PIN_CHANGE">PIN Change</SelectItem>
+                              <SelectItem value="CARDLESS_WITHDRAW">Cardless Cash-Out</SelectItem>
                             </SelectContent>
                           </Select>
                         </FormControl>
@@ -229,7 +313,7 @@ export function AtmForm () {
                       <FormItem>
                         <FormLabel htmlFor="amount">Transaction Amount</FormLabel>
                         <FormControl>
-                          <Input aria-describedby="amount" id="amount" min="0" type="number" step="0.01" placeholder="100.00" {...field}/>
+                          <Input aria-describedby="amount" id="amount" min="0" type="number" step="any" placeholder="100.00" {...field}/>
                         </FormControl>
                         <FormMessage />
                       </FormItem>
@@ -251,6 +335,111 @@ export function AtmForm () {
                     )}
                   />
                 </div>
+                <div className="flex flex-col space-y-1.5">
+                  <FormField
+                    control={form.control}
+                    name="pin"
+                    render={({ field }) => (
+                      <FormItem>
+                        <FormLabel htmlFor="pin">PIN</FormLabel>
+                        <FormControl>
+                          <Input aria-describedby="pin" id="pin" type="password" autoComplete="off" {...field}/>
+                        </FormControl>
+                        <FormMessage />
+                      </FormItem>
+                    )}
+                  />
+                </div>
+                <div className="flex flex-col space-y-1.5">
+                  <FormField
+                    control={form.control}
+                    name="newPin"
+                    render={({ field }) => (
+                      <FormItem>
+                        <FormLabel htmlFor="new-pin">New PIN</FormLabel>
+                        <FormControl>
+                          <Input aria-describedby="new-pin" id="new-pin" type="password" autoComplete="off" {...field}/>
+                        </FormControl>
+                        <FormMessage />
+                      </FormItem>
+                    )}
+                  />
+                </div>
+                <div className="flex flex-col space-y-1.5">
+                  <FormField
+                    control={form.control}
+                    name="referenceCode"
+                    render={({ field }) => (
+                      <FormItem>
+                        <FormLabel htmlFor="reference-code">Reference Code</FormLabel>
+                        <FormControl>
+                          <Input aria-describedby="reference-code" id="reference-code" {...field}/>
+                        </FormControl>
+                        <FormMessage />
+                      </FormItem>
+                    )}
+                  />
+                </div>
+                <div className="flex flex-col space-y-1.5">
+                  <FormField
+                    control={form.control}
+                    name="mobileNumber"
+                    render={({ field }) => (
+                      <FormItem>
+                        <FormLabel htmlFor="mobile-number">Mobile Number</FormLabel>
+                        <FormControl>
+                          <Input aria-describedby="mobile-number" id="mobile-number" placeholder="09171234567" {...field}/>
+                        </FormControl>
+                        <FormMessage />
+                      </FormItem>
+                    )}
+                  />
+                </div>
+                <div className="flex flex-col space-y-1.5">
+                  <FormField
+                    control={form.control}
+                    name="otp"
+                    render={({ field }) => (
+                      <FormItem>
+                        <FormLabel htmlFor="otp">OTP</FormLabel>
+                        <FormControl>
+                          <Input aria-describedby="otp" id="otp" type="password" autoComplete="off" {...field}/>
+                        </FormControl>
+                        <FormMessage />
+                      </FormItem>
+                    )}
+                  />
+                </div>
+                <div className="flex flex-col space-y-1.5">
+                  <FormField
+                    control={form.control}
+                    name="depositItems"
+                    render={({ field }) => (
+                      <FormItem>
+                        <FormLabel htmlFor="deposit-items">Deposit Items</FormLabel>
+                        <FormControl>
+                          <Input aria-describedby="deposit-items" id="deposit-items" min="0" type="number" step="1" placeholder="0" {...field}/>
+                        </FormControl>
+                        <FormMessage />
+                      </FormItem>
+                    )}
+                  />
+                </div>
+                <div className="flex flex-col space-y-1.5">
+                  <FormField
+                    control={form.control}
+                    name="chequeNumber"
+                    render={({ field }) => (
+                      <FormItem>
+                        <FormLabel htmlFor="cheque-number">Cheque Number</FormLabel>
+                        <FormControl>
+                          <Input aria-describedby="cheque-number" id="cheque-number" placeholder="000123" {...field}/>
+                        </FormControl>
+                        <FormMessage />
+                      </FormItem>
+                    )}
+                  />
+                </div>
                 <div className="flex flex-col space-y-1.5">
                   <FormField
                     control={form.control}
@@ -264,8 +453,7 @@ export function AtmForm () {
                               <SelectValue placeholder="Select Currency" />
                             </SelectTrigger>
                             <SelectContent position="popper">
-                              <SelectItem value="608">PHP</SelectItem>
-                              <SelectItem value="840">USD</SelectItem>
+                              {currencies.map(c => <SelectItem key={c.code} value={c.code}>{c.alpha}</SelectItem>)}
                             </SelectContent>
                           </Select>
                         </FormControl>
@@ -274,6 +462,58 @@ export function AtmForm () {
                     )}
                   />
                 </div>
+                <div className="flex flex-col space-y-1.5">
+                  <FormField
+                    control={form.control}
+                    name="billingCurrencyCode"
+                    render={({ field }) => (
+                      <FormItem>
+                        <FormLabel htmlFor="billing-currency">Billing Currency</FormLabel>
+                        <FormControl>
+                          <Select onValueChange={field.onChange} defaultValue={field.value}>
+                            <SelectTrigger id="billing-currency" aria-controls='billing-currency'>
+                              <SelectValue placeholder="Same as Currency" />
+                            </SelectTrigger>
+                            <SelectContent position="popper">
+                              {currencies.map(c => <SelectItem key={c.code} value={c.code}>{c.alpha}</SelectItem>)}
+                            </SelectContent>
+                          </Select>
+                        </FormControl>
+                        <FormMessage />
+                      </FormItem>
+                    )}
+                  />
+                </div>
+                <div className="flex flex-col space-y-1.5">
+                  <FormField
+                    control={form.control}
+                    name="billingAmount"
+                    render={({ field }) => (
+                      <FormItem>
+                        <FormLabel htmlFor="billing-amount">Billing Amount</FormLabel>
+                        <FormControl>
+                          <Input aria-describedby="billing-amount" id="billing-amount" min="0" type="number" step="any" placeholder="0.00" {...field}/>
+                        </FormControl>
+                        <FormMessage />
+                      </FormItem>
+                    )}
+                  />
+                </div>
+                <div className="flex flex-col space-y-1.5">
+                  <FormField
+                    control={form.control}
+                    name="conversionRate"
+                    render={({ field }) => (
+                      <FormItem>
+                        <FormLabel htmlFor="conversion-rate">Conversion Rate</FormLabel>
+                        <FormControl>
+                          <Input aria-describedby="conversion-rate" id="conversion-rate" min="0" type="number" step="any" placeholder="0.0178" {...field}/>
+                        </FormControl>
+                        <FormMessage />
+                      </FormItem>
+                    )}
+                  />
+                </div>
                 <div className="flex flex-col space-y-1.5">
                   <FormField
                     control={form.control}
@@ -416,7 +656,19 @@ export function AtmForm () {
             <hr className='my-10 mx-10 h-1 rounded bg-slate-700' />
             <CardFooter className="flex justify-between">
               <Button variant={'destructive'} type='button' onClick={reset}>Clear</Button>
-              <Button type='submit'>Submit</Button>
+              <div className='flex space-x-4'>
+                <Select onValueChange={setFault} value={fault}>
+                  <SelectTrigger id="fault" aria-controls='fault' className='w-[220px]'>
+                    <SelectValue placeholder="Select Fault Case" />
+                  </SelectTrigger>
+                  <SelectContent position="popper">
+                    {switchFaults.map(c => <SelectItem key={c.fault} value={c.fault} title={c.description}>{c.fault}</SelectItem>)}
+                  </SelectContent>
+                </Select>
+                <Button variant='outline' type='button' disabled={!switchFaults.some(c => c.fault === fault)} onClick={form.handleSubmit(onFault)}>Send with Fault</Button>
+                <Button variant='outline' type='button' onClick={form.handleSubmit(onAdvice)}>Send as Advice</Button>
+                <Button type='submit'>Submit</Button>
+              </div>
             </CardFooter>
           </Card>
         </form>
@@ -1,5 +1,7 @@
 import { AlertDialog, AlertDialogCancel, AlertDialogContent, AlertDialogDescription, AlertDialogFooter, AlertDialogHeader, AlertDialogTitle } from './ui/alert-dialog'
 import { main } from '../../wailsjs/go/models'
+import { GetCurrencies } from '../../wailsjs/go/main/App'
+import { useEffect, useState } from 'react'
 
 type Props = {
     atmResponse?: main.AtmResponse
@@ -9,6 +11,14 @@ type Props = {
 
 const AtmResponseDialog = ({atmResponse, isOpen, setOpen}: Props) => {
 
+  const [currencies, setCurrencies] = useState<main.CurrencyInfo[]>([])
+
+  useEffect(() => {
+    GetCurrencies().then(setCurrencies).catch(() => {})
+  }, [])
+
+  const exponent = currencies.find(c => c.code === atmResponse?.currency)?.exponent ?? 2
+
   return (
     <AlertDialog open={isOpen} onOpenChange={() => setOpen(!isOpen)}>
       <AlertDialogContent>
@@ -33,6 +43,32 @@ const AtmResponseDialog = ({atmResponse, isOpen, setOpen}: Props) => {
               <label>Balance:</label>
               <span>{atmResponse?.balance}</span>
             </div>
+            {atmResponse?.referenceCode && (
+              <div className='flex justify-between'>
+                <label>Reference Code:</label>
+                <span>{atmResponse.referenceCode}</span>
+              </div>
+            )}
+            {atmResponse?.statement && (
+              <table className='mt-4 w-full text-sm'>
+                <thead>
+                  <tr>
+                    <th className='text-left'>Date</th>
+                    <th className='text-left'>Description</th>
+                    <th className='text-right'>Amount</th>
+                  </tr>
+                </thead>
+                <tbody>
+                  {atmResponse.statement.map((e, i) => (
+                    <tr key={i}>
+                      <td>{e.date}</td>
+                      <td>{e.description}</td>
+                      <td className='text-right'>{e.credit ? '' : '-'}{e.amount.toFixed(exponent)}</td>
+                    </tr>
+                  ))}
+                </tbody>
+              </table>
+            )}
           </div>
         </AlertDialogDescription>
         <AlertDialogFooter>
@@ -0,0 +1,58 @@
+import { useEffect, useState } from 'react'
+import { AlertDialog, AlertDialogAction, AlertDialogCancel, AlertDialogContent, AlertDialogDescription, AlertDialogFooter, AlertDialogHeader, AlertDialogTitle } from './ui/alert-dialog'
+import { Input } from './ui/input'
+import { Label } from './ui/label'
+import { main } from '../../wailsjs/go/models'
+
+type Props = {
+  message?: main.Message
+  isOpen: boolean
+  setOpen: (isOpen: boolean) => void
+  onConfirm: (completion: main.DepositCompletion) => void
+}
+
+const DepositDialog = ({message, isOpen, setOpen, onConfirm}: Props) => {
+  const [countedAmount, setCountedAmount] = useState('0')
+  const [items, setItems] = useState('0')
+
+  useEffect(() => {
+    setCountedAmount(String(message?.transactionAmount ?? 0))
+    setItems(String(message?.depositItems ?? 0))
+  }, [message])
+
+  const confirm = () => {
+    onConfirm(main.DepositCompletion.createFrom({
+      countedAmount: Number(countedAmount) || 0,
+      items: Number(items) || 0,
+    }))
+  }
+
+  return (
+    <AlertDialog open={isOpen} onOpenChange={() => setOpen(!isOpen)}>
+      <AlertDialogContent>
+        <AlertDialogHeader>
+          <AlertDialogTitle>Deposit Completion</AlertDialogTitle>
+        </AlertDialogHeader>
+        <AlertDialogDescription>
+          {message?.transaction} {message?.traceNumber} requested {message?.transactionAmount}. Enter what the device counted.
+        </AlertDialogDescription>
+        <div className='flex flex-col space-y-4'>
+          <div className='flex flex-col space-y-1.5'>
+            <Label htmlFor='deposit-counted'>Counted Amount</Label>
+            <Input id='deposit-counted' value={countedAmount} onChange={e => setCountedAmount(e.target.value)}/>
+          </div>
+          <div className='flex flex-col space-y-1.5'>
+            <Label htmlFor='deposit-counted-items'>Items</Label>
+            <Input id='deposit-counted-items' value={items} onChange={e => setItems(e.target.value)}/>
+          </div>
+        </div>
+        <AlertDialogFooter>
+          <AlertDialogCancel>Cancel</AlertDialogCancel>
+          <AlertDialogAction onClick={confirm}>Queue Advice</AlertDialogAction>
+        </AlertDialogFooter>
+      </AlertDialogContent>
+    </AlertDialog>
+  )
+}
+
+export default DepositDialog
@@ -1,12 +1,17 @@
 
 import { ColumnDef } from '@tanstack/react-table'
 import { useEffect, useState } from 'react'
-import { GetMessages } from '../../wailsjs/go/main/App'
+import { GetMessageLifecycle, GetMessages, GetPreAuth, SendDepositCompletion, SendPreAuthFollowUp } from '../../wailsjs/go/main/App'
 import { DataTable } from './data-table'
 import { main } from '../../wailsjs/go/models'
 import { Button } from './ui/button'
-import { SendReversalMessage } from '../../wailsjs/go/main/App'
+import { SendReversalAdvice, SendReversalMessage } from '../../wailsjs/go/main/App'
 import AtmResponseDialog from './atm-response-dialog'
+import ReversalDialog from './reversal-dialog'
+import LifecycleDialog from './lifecycle-dialog'
+import PreAuthDialog from './pre-auth-dialog'
+import DepositDialog from './deposit-dialog'
+import { Saf } from './saf'
 import { useRecoilState } from 'recoil'
 import { loadingState, messageState, pageState } from '@/store/state'
 import { useToast } from './ui/use-toast'
@@ -40,15 +45,36 @@ export function History () {
       accessorKey: 'transaction',
       header: () => <div className="text-center">Transaction</div>,
     },
+    {
+      accessorKey: 'responseCode',
+      header: () => <div className="text-center">Response</div>,
+    },
+    {
+      accessorKey: 'reversalStatus',
+      header: () => <div className="text-center">Reversal</div>,
+      cell: ({row}) => row.original.parentId
+        ? `${row.original.mti} of #${row.original.parentId}`
+        : `${row.original.reversalStatus || ''} ${row.original.reversalResponseCode || ''}`,
+    },
     {
       accessorKey: 'action',
       header: () => <div className="text-center">Action</div>,
       cell: ({row}) => {
-        const disabled = String(row.getValue('transaction')).includes('REVERSAL')
+        const isReversal = Boolean(row.original.parentId)
+        const status = row.original.reversalStatus
+        const disabled = isReversal || status === 'PENDING' || status === 'REVERSED'
         return (
           <div className='flex justify-center space-x-5'>
-            <Button variant="default" disabled={disabled} onClick={() => loadMessage(row.original)}>Load</Button> 
-            <Button onClick={() => sendMessage(row.getValue('id'))} variant="destructive" disabled={disabled}>Revert</Button>
+            <Button variant="default" disabled={isReversal} onClick={() => loadMessage(row.original)}>Load</Button> 
+            <Button onClick={() => openReversal(row.original, false)} variant="destructive" disabled={disabled}>Revert</Button>
+            <Button onClick={() => openReversal(row.original, true)} variant="outline" disabled={disabled}>Reversal Advice</Button>
+            <Button onClick={() => openLifecycle(row.original.id!)} variant="outline">Lifecycle</Button>
+            {row.original.transaction === 'PRE_AUTH' && !isReversal && (
+              <Button onClick={() => openPreAuth(row.original.id!)} variant="outline">Pre-Auth</Button>
+            )}
+            {['DEPOSIT', 'CHEQUE_DEPOSIT'].includes(row.original.transaction) && !isReversal && !row.original.authorizationId && (
+              <Button onClick={() => openDeposit(row.original)} variant="outline">Complete Deposit</Button>
+            )}
           </div>
         )
       }
@@ -61,16 +87,103 @@ export function History () {
   const [atmResponse, setAtmResponse] = useState<main.AtmResponse>()
   const [, setMessage] = useRecoilState(messageState)
   const [, setPage] = useRecoilState(pageState)
+  const [reversal, setReversal] = useState<main.Message>()
+  const [isAdvice, setAdvice] = useState(false)
+  const [isReversalOpen, setReversalOpen] = useState(false)
+  const [lifecycle, setLifecycle] = useState<main.MessageLifecycle>()
+  const [isLifecycleOpen, setLifecycleOpen] = useState(false)
+
+  const [preAuth, setPreAuth] = useState<main.PreAuth>()
+  const [isPreAuthOpen, setPreAuthOpen] = useState(false)
+
+  const openPreAuth = async (id: number) => {
+    try {
+      setPreAuth(await GetPreAuth(id))
+      setPreAuthOpen(true)
+    } catch(error:any) {
+      toast({
+        description: error,
+      })
+    }
+  }
+
+  const sendFollowUp = async (followUp: main.PreAuthFollowUp) => {
+    setLoading(true)
+    try {
+      const response = await SendPreAuthFollowUp(preAuth!.preAuth.id!, followUp)
+      setPreAuthOpen(false)
+      setMessages(await GetMessages(1))
+      setAtmResponse(response)
+      setOpen(true)
+    } catch(error:any) {
+      toast({
+        description: error,
+      })
+    } finally  {
+      setLoading(false)
+    }
+  }
 
-  const sendMessage = async (id: number) =>{
+  const [deposit, setDeposit] = useState<main.Message>()
+  const [isDepositOpen, setDepositOpen] = useState(false)
+
+  const openDeposit = (message: main.Message) => {
+    setDeposit(message)
+    setDepositOpen(true)
+  }
+
+  const completeDeposit = async (completion: main.DepositCompletion) => {
+    setLoading(true)
+    try {
+      const item = await SendDepositCompletion(deposit!.id!, completion)
+      setMessages(await GetMessages(1))
+      toast({
+        description: `Deposit completion ${item.mti} queued as SAF item ${item.id}.`,
+      })
+    } catch(error:any) {
+      toast({
+        description: error,
+      })
+    } finally  {
+      setLoading(false)
+    }
+  }
+
+  const openLifecycle = async (id: number) => {
+    try {
+      setLifecycle(await GetMessageLifecycle(id))
+      setLifecycleOpen(true)
+    } catch(error:any) {
+      toast({
+        description: error,
+      })
+    }
+  }
+
+  const openReversal = (message: main.Message, advice: boolean) => {
+    setReversal(message)
+    setAdvice(advice)
+    setReversalOpen(true)
+  }
+
+  const reverse = (options: main.ReversalOptions) => {
+    if (isAdvice) {
+      queueAdvice(reversal!.id!, options)
+    } else {
+      sendMessage(reversal!.id!, options)
+    }
+  }
+
+  const sendMessage = async (id: number, options: main.ReversalOptions) =>{
     let response
     setLoading(true)
     try {
-      response = await SendReversalMessage(id)
+      response = await SendReversalMessage(id, options)
       setMessages(await GetMessages(1))
       setAtmResponse(response)
       setOpen(true)
     } catch(error:any) {
+      GetMessages(1).then(setMessages)
       toast({
         description: error,
       })
@@ -80,6 +193,23 @@ export function History () {
     
   }
 
+  const queueAdvice = async (id: number, options: main.ReversalOptions) => {
+    setLoading(true)
+    try {
+      const item = await SendReversalAdvice(id, options)
+      setMessages(await GetMessages(1))
+      toast({
+        description: `Reversal advice ${item.mti} queued as SAF item ${item.id}.`,
+      })
+    } catch(error:any) {
+      toast({
+        description: error,
+      })
+    } finally  {
+      setLoading(false)
+    }
+  }
+
   const loadMessage = (message: main.Message) => {
     setMessage(message)
     setPage('home')
@@ -97,8 +227,12 @@ export function History () {
    
 
   return (
-    <>
+    <div className='flex flex-col w-full space-y-10'>
       <AtmResponseDialog isOpen={isOpen} setOpen={setOpen} atmResponse={atmResponse}/>
+      <LifecycleDialog lifecycle={lifecycle} isOpen={isLifecycleOpen} setOpen={setLifecycleOpen}/>
+      <PreAuthDialog preAuth={preAuth} isOpen={isPreAuthOpen} setOpen={setPreAuthOpen} onSend={sendFollowUp}/>
+      <DepositDialog message={deposit} isOpen={isDepositOpen} setOpen={setDepositOpen} onConfirm={completeDeposit}/>
+      <ReversalDialog message={reversal} title={isAdvice ? 'Reversal Advice' : 'Reversal'} isOpen={isReversalOpen} setOpen={setReversalOpen} onConfirm={reverse}/>
       <Card className='w-full'>
         <CardHeader>
           <CardTitle className="text-center">History</CardTitle>
@@ -107,7 +241,8 @@ export function History () {
           <DataTable columns={columns} data={messages} />
         </CardContent>
       </Card>
-    </>
+      <Saf />
+    </div>
   
   )
 }
\ No newline at end of file
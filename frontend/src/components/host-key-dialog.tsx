@@ -0,0 +1,61 @@
+import { useEffect, useState } from 'react'
+import { AlertDialog, AlertDialogAction, AlertDialogCancel, AlertDialogContent, AlertDialogDescription, AlertDialogFooter, AlertDialogHeader, AlertDialogTitle } from './ui/alert-dialog'
+import { AnswerHostKey } from '../../wailsjs/go/main/App'
+import { EventsOff, EventsOn } from '../../wailsjs/runtime'
+import { HostKeyPrompt } from '@/lib/message'
+import { useToast } from './ui/use-toast'
+
+// HostKeyDialog asks whether to trust the key of an SSH host that is not in
+// the known hosts yet.
+const HostKeyDialog = () => {
+
+  const [prompt, setPrompt] = useState<HostKeyPrompt>()
+  const { toast } = useToast()
+
+  useEffect(() => {
+    EventsOn('hostkey', setPrompt)
+    return () => {
+      EventsOff('hostkey')
+    }
+  }, [])
+
+  const answer = async (accepted: boolean) => {
+    setPrompt(undefined)
+    try {
+      await AnswerHostKey(accepted)
+    } catch(error: any) {
+      toast({
+        description: error,
+      })
+    }
+  }
+
+  return (
+    <AlertDialog open={!!prompt}>
+      <AlertDialogContent>
+        <AlertDialogHeader>
+          <AlertDialogTitle>Unknown Host Key</AlertDialogTitle>
+        </AlertDialogHeader>
+        <AlertDialogDescription>
+          <div className='flex flex-col w-full space-y-2'>
+            <span>The authenticity of {prompt?.host} cannot be established. Trust it only if the fingerprint matches the one of the host.</span>
+            <div className='flex justify-between'>
+              <label>Key Type:</label>
+              <span>{prompt?.keyType}</span>
+            </div>
+            <div className='flex justify-between'>
+              <label>Fingerprint:</label>
+              <span className='break-all'>{prompt?.fingerprint}</span>
+            </div>
+          </div>
+        </AlertDialogDescription>
+        <AlertDialogFooter>
+          <AlertDialogCancel onClick={() => answer(false)}>Reject</AlertDialogCancel>
+          <AlertDialogAction onClick={() => answer(true)}>Trust and Pin</AlertDialogAction>
+        </AlertDialogFooter>
+      </AlertDialogContent>
+    </AlertDialog>
+  )
+}
+
+export default HostKeyDialog
@@ -0,0 +1,82 @@
+import { ColumnDef } from '@tanstack/react-table'
+import { useEffect, useState } from 'react'
+import { GetKnownHosts, RemoveKnownHost } from '../../wailsjs/go/main/App'
+import { main } from '../../wailsjs/go/models'
+import { DataTable } from './data-table'
+import { Button } from './ui/button'
+import { useToast } from './ui/use-toast'
+import {
+  Card,
+  CardContent,
+  CardHeader,
+  CardTitle
+} from '@/components/ui/card'
+
+// KnownHosts lists the SSH host keys pinned by the app, ~/.ssh/known_hosts is
+// trusted as well but not managed here.
+const KnownHosts = () => {
+  const columns: ColumnDef<main.KnownHost>[] = [
+    {
+      accessorKey: 'hosts',
+      header: () => <div className="text-center">Hosts</div>,
+    },
+    {
+      accessorKey: 'keyType',
+      header: () => <div className="text-center">Key Type</div>,
+    },
+    {
+      accessorKey: 'fingerprint',
+      header: () => <div className="text-center">Fingerprint</div>,
+      cell: ({row}) => <div className='text-xs break-all'>{row.original.fingerprint}</div>,
+    },
+    {
+      accessorKey: 'action',
+      header: () => <div className="text-center">Action</div>,
+      cell: ({row}) => (
+        <div className='flex justify-center'>
+          <Button type='button' variant="destructive" onClick={() => remove(row.original)}>Remove</Button>
+        </div>
+      )
+    },
+  ]
+
+  const [knownHosts, setKnownHosts] = useState<main.KnownHost[]>([])
+  const { toast } = useToast()
+
+  const refresh = () => GetKnownHosts().then(setKnownHosts).catch((error: any) => {
+    toast({
+      description: error,
+    })
+  })
+
+  const remove = async (knownHost: main.KnownHost) => {
+    try {
+      await RemoveKnownHost(knownHost.hosts, knownHost.fingerprint)
+      toast({
+        description: `Host key of ${knownHost.hosts} has been removed, it will be asked again on the next connection.`,
+      })
+      await refresh()
+    } catch(error: any) {
+      toast({
+        description: error,
+      })
+    }
+  }
+
+  useEffect(() => {
+    refresh()
+  }, [])
+
+  return (
+    <Card className='mt-10'>
+      <CardHeader>
+        <CardTitle className="text-center">Known Hosts</CardTitle>
+      </CardHeader>
+      <CardContent>
+        <DataTable columns={columns} data={knownHosts} />
+      </CardContent>
+    </Card>
+  )
+}
+
+export default KnownHosts
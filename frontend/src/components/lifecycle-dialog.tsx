@@ -0,0 +1,60 @@
+import { AlertDialog, AlertDialogCancel, AlertDialogContent, AlertDialogDescription, AlertDialogFooter, AlertDialogHeader, AlertDialogTitle } from './ui/alert-dialog'
+import { main } from '../../wailsjs/go/models'
+
+type Props = {
+  lifecycle?: main.MessageLifecycle
+  isOpen: boolean
+  setOpen: (isOpen: boolean) => void
+}
+
+const LifecycleDialog = ({lifecycle, isOpen, setOpen}: Props) => {
+  const original = lifecycle?.original
+
+  return (
+    <AlertDialog open={isOpen} onOpenChange={() => setOpen(!isOpen)}>
+      <AlertDialogContent>
+        <AlertDialogHeader>
+          <AlertDialogTitle>Message {original?.id}</AlertDialogTitle>
+        </AlertDialogHeader>
+        <AlertDialogDescription>
+          <div className='flex flex-col w-full space-y-4'>
+            <div className='flex flex-col'>
+              <div className='flex justify-between'>
+                <label>Original:</label>
+                <span>{original?.mti} {original?.transaction} {original?.transactionAmount}</span>
+              </div>
+              <div className='flex justify-between'>
+                <label>Trace Number:</label>
+                <span>{original?.traceNumber}</span>
+              </div>
+              <div className='flex justify-between'>
+                <label>Response Code:</label>
+                <span>{original?.responseCode || '-'}</span>
+              </div>
+            </div>
+            <div className='flex flex-col'>
+              {lifecycle?.reversals.length === 0 && <span>No reversal has been sent.</span>}
+              {lifecycle?.reversals.map(r => (
+                <div key={r.id} className='flex justify-between'>
+                  <label>{r.mti} #{r.id}:</label>
+                  <span>
+                    {r.replacementAmount ? `partial, dispensed ${r.replacementAmount}` : 'full'}, reason {r.reversalReason || '-'}, response {r.responseCode || '-'}
+                  </span>
+                </div>
+              ))}
+            </div>
+            <div className='flex justify-between'>
+              <label>Final State:</label>
+              <span>{lifecycle?.status || 'NOT REVERSED'} {lifecycle?.responseCode}</span>
+            </div>
+          </div>
+        </AlertDialogDescription>
+        <AlertDialogFooter>
+          <AlertDialogCancel>Close</AlertDialogCancel>
+        </AlertDialogFooter>
+      </AlertDialogContent>
+    </AlertDialog>
+  )
+}
+
+export default LifecycleDialog
@@ -1,9 +1,11 @@
 import { useRecoilState } from 'recoil'
 import { ThemeButton } from './theme-button'
 import { Landmark, Cable } from 'lucide-react'
-import { loadingState, pageState, tunnelState } from '@/store/state'
+import { loadingState, pageState, tunnelState, tunnelStatusState } from '@/store/state'
 import { Button } from './ui/button'
-import { CloseTunnel, PingTunnel, UseTunnel } from '../../wailsjs/go/main/App'
+import { CloseTunnel, GetTunnels, GetVaultStatus, PingTunnel, UseTunnel } from '../../wailsjs/go/main/App'
+import { main } from '../../wailsjs/go/models'
+import { LinkMessage } from '@/lib/message'
 import { useToast } from '@/components/ui/use-toast'
 import { EventsOn } from '../../wailsjs/runtime'
 import { useEffect } from 'react'
@@ -13,6 +15,7 @@ const Navigation = () => {
 
   const [, setPage] = useRecoilState(pageState)
   const [tunnel, setTunnel] = useRecoilState(tunnelState)
+  const [statuses, setStatuses] = useRecoilState(tunnelStatusState)
   const [, setLoading] = useRecoilState(loadingState)
   const { toast } = useToast()
   EventsOn('tunnel', (tunnel: boolean, withToast: boolean = true) => {
@@ -24,9 +27,48 @@ const Navigation = () => {
     }
   })
 
+  EventsOn('tunnel:status', (status: main.TunnelStatus) => {
+    setStatuses(current => ({ ...current, [status.tunnel.name]: status }))
+    if(status.state === 'down' && status.error) {
+      toast({
+        description: `Tunnel ${status.tunnel.name} is down: ${status.error}` + (status.attempts > 0 ? ` (reconnect attempt ${status.attempts})` : ''),
+      })
+    }
+  })
+
+  EventsOn('link:message', (message: LinkMessage) => {
+    if(message.inbound && !message.autoReply && message.message.mti.charAt(1) !== '8') {
+      toast({
+        description: `${message.switch} sent an unsolicited ${message.message.mti}, see the server page.`,
+      })
+    }
+  })
+
+  useEffect(() => {
+    GetVaultStatus().then(status => {
+      if (status.initialized && !status.unlocked) {
+        toast({
+          description: 'Secrets are locked, unlock them in the settings.',
+        })
+      }
+    }).catch(() => {})
+  }, [])
+
   useEffect(() => {
     PingTunnel().then()
+    const refresh = () => GetTunnels().then(tunnels => {
+      setStatuses(Object.fromEntries(tunnels.map(t => [t.tunnel.name, t])))
+    }).catch(() => {})
+    refresh()
+    const interval = setInterval(refresh, 5000)
+    return () => clearInterval(interval)
   }, [])
+
+  // a tunnel that is reconnecting is still running, it is down but has attempts
+  const running = Object.values(statuses).some(s => s.state !== 'down' || s.attempts > 0)
+  const degraded = Object.values(statuses).some(s => s.state === 'connecting' || s.state === 'degraded' || (s.state === 'down' && s.attempts > 0))
+  const title = Object.values(statuses).map(s => `${s.tunnel.name}: ${s.state}` + (s.missed > 0 ? ` (${s.missed} keepalives missed)` : '')
+    + (s.metrics ? `, ${s.metrics.active} active of ${s.metrics.total} connections, ${s.metrics.bytesIn} B in / ${s.metrics.bytesOut} B out, ${s.metrics.dialFailures} dial failures` : '')).join('\n')
   
 
   return (
@@ -36,14 +78,17 @@ const Navigation = () => {
         <div className='flex space-x-10'>
           <span className='text-xl hover:cursor-pointer' onClick={()=> setPage('home')}>ATM</span>
           <span className='text-xl hover:cursor-pointer' onClick={()=> setPage('history')}>HISTORY</span>
+          <span className='text-xl hover:cursor-pointer' onClick={()=> setPage('server')}>SERVER</span>
+          <span className='text-xl hover:cursor-pointer' onClick={()=> setPage('recon')}>RECON</span>
+          <span className='text-xl hover:cursor-pointer' onClick={()=> setPage('tunnels')}>TUNNELS</span>
           <span className='text-xl hover:cursor-pointer' onClick={()=> setPage('settings')}>SETTINGS</span>
         </div>
       </div>
       <div className='flex items-center'>
-        <Button variant="ghost" size="icon" title='Tunnel' onClick={async () => {
+        <Button variant="ghost" size="icon" title={title || 'Tunnel'} onClick={async () => {
           try {
             setLoading(true)
-            if(!tunnel) {
+            if(!tunnel && !running) {
               await UseTunnel()
             }else {
               await CloseTunnel()
@@ -56,7 +101,7 @@ const Navigation = () => {
             setLoading(false)   
           }
         }}>
-          <Cable color={tunnel ? 'green': 'red'} />
+          <Cable color={degraded ? 'orange' : tunnel ? 'green': 'red'} />
         </Button>
         <ThemeButton />
       </div>
@@ -0,0 +1,73 @@
+import { useEffect, useState } from 'react'
+import { AlertDialog, AlertDialogCancel, AlertDialogContent, AlertDialogDescription, AlertDialogFooter, AlertDialogHeader, AlertDialogTitle } from './ui/alert-dialog'
+import { Button } from './ui/button'
+import { Input } from './ui/input'
+import { Label } from './ui/label'
+import { main } from '../../wailsjs/go/models'
+import { Transaction } from '@/lib/message'
+
+type Props = {
+  preAuth?: main.PreAuth
+  isOpen: boolean
+  setOpen: (isOpen: boolean) => void
+  onSend: (followUp: main.PreAuthFollowUp) => void
+}
+
+const PreAuthDialog = ({preAuth, isOpen, setOpen, onSend}: Props) => {
+  const [amount, setAmount] = useState('0')
+
+  useEffect(() => {
+    setAmount(String(preAuth?.authorized ?? 0))
+  }, [preAuth])
+
+  const send = (transaction: Transaction) => {
+    onSend(main.PreAuthFollowUp.createFrom({
+      transaction,
+      amount: Number(amount) || 0,
+    }))
+  }
+
+  const disabled = !preAuth || preAuth.closed || !preAuth.authorized
+
+  return (
+    <AlertDialog open={isOpen} onOpenChange={() => setOpen(!isOpen)}>
+      <AlertDialogContent>
+        <AlertDialogHeader>
+          <AlertDialogTitle>Pre-Auth {preAuth?.preAuth.id}</AlertDialogTitle>
+        </AlertDialogHeader>
+        <AlertDialogDescription>
+          <div className='flex flex-col w-full space-y-4'>
+            <div className='flex flex-col'>
+              <div className='flex justify-between'>
+                <label>Authorized:</label>
+                <span>{preAuth?.authorized}</span>
+              </div>
+              <div className='flex justify-between'>
+                <label>State:</label>
+                <span>{preAuth?.closed ? 'CLOSED' : 'OPEN'}</span>
+              </div>
+              {preAuth?.followUps.map(f => (
+                <div key={f.id} className='flex justify-between'>
+                  <label>{f.mti} #{f.id}:</label>
+                  <span>{f.transaction} {f.transactionAmount}, response {f.responseCode || '-'}</span>
+                </div>
+              ))}
+            </div>
+            <div className='flex flex-col space-y-1.5'>
+              <Label htmlFor='pre-auth-amount'>Amount</Label>
+              <Input id='pre-auth-amount' value={amount} onChange={e => setAmount(e.target.value)}/>
+            </div>
+          </div>
+        </AlertDialogDescription>
+        <AlertDialogFooter>
+          <AlertDialogCancel>Close</AlertDialogCancel>
+          <Button variant='outline' disabled={disabled} onClick={() => send(Transaction.INCREMENTAL_AUTH)}>Incremental</Button>
+          <Button disabled={disabled} onClick={() => send(Transaction.PRE_AUTH_COMPLETION)}>Complete</Button>
+          <Button variant='destructive' disabled={disabled} onClick={() => send(Transaction.PRE_AUTH_CANCEL)}>Cancel Pre-Auth</Button>
+        </AlertDialogFooter>
+      </AlertDialogContent>
+    </AlertDialog>
+  )
+}
+
+export default PreAuthDialog
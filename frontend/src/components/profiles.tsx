@@ -0,0 +1,130 @@
+import { Button } from '@/components/ui/button'
+import { Card, CardContent, CardHeader, CardTitle } from '@/components/ui/card'
+import { Input } from '@/components/ui/input'
+import { Label } from '@/components/ui/label'
+import {
+  Select,
+  SelectContent,
+  SelectItem,
+  SelectTrigger,
+  SelectValue
+} from '@/components/ui/select'
+import { useEffect, useState } from 'react'
+import { useRecoilState } from 'recoil'
+import { loadingState } from '@/store/state'
+import { CreateProfile, DeleteProfile, ExportProfiles, GetActiveProfile, GetProfiles, ImportProfiles, SwitchProfile } from '../../wailsjs/go/main/App'
+import { useToast } from '@/components/ui/use-toast'
+
+type Props = {
+  onChange: () => void
+}
+
+const Profiles = ({ onChange }: Props) => {
+
+  const [profiles, setProfiles] = useState<string[]>([])
+  const [active, setActive] = useState('')
+  const [selected, setSelected] = useState('')
+  const [name, setName] = useState('')
+  const [, setLoading] = useRecoilState(loadingState)
+  const { toast } = useToast()
+
+  const refresh = async () => {
+    const _profiles = await GetProfiles()
+    const _active = await GetActiveProfile()
+    setProfiles(_profiles.map(p => p.name))
+    setActive(_active)
+    setSelected(_active)
+  }
+
+  useEffect(() => {
+    refresh().catch((error: any) => {
+      toast({
+        description: error,
+      })
+    })
+  }, [])
+
+  const run = async (action: () => Promise<string>) => {
+    try {
+      setLoading(true)
+      const description = await action()
+      await refresh()
+      onChange()
+      if (description) {
+        toast({
+          description,
+        })
+      }
+    } catch(error: any) {
+      toast({
+        description: error,
+      })
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  const switchProfile = () => run(async () => {
+    await SwitchProfile(selected)
+    return `Switched to profile ${selected}, links and tunnels were closed.`
+  })
+
+  const createProfile = () => run(async () => {
+    await CreateProfile(name, active)
+    setName('')
+    return `Profile ${name} has been created from ${active}.`
+  })
+
+  const deleteProfile = () => run(async () => {
+    await DeleteProfile(selected)
+    return `Profile ${selected} has been deleted.`
+  })
+
+  const importProfiles = () => run(async () => {
+    const count = await ImportProfiles()
+    return count > 0 ? `${count} profiles have been imported.` : ''
+  })
+
+  const exportProfiles = () => run(async () => {
+    const path = await ExportProfiles()
+    return path ? `Profiles have been exported to ${path}.` : ''
+  })
+
+  return (
+    <Card className='mb-10'>
+      <CardHeader>
+        <CardTitle className="text-center">Profiles</CardTitle>
+      </CardHeader>
+      <CardContent>
+        <div className="grid w-full items-end gap-x-10 gap-y-4 sm:grid-cols-2">
+          <div className="flex flex-col space-y-1.5">
+            <Label htmlFor='profile'>Profile</Label>
+            <Select onValueChange={setSelected} value={selected}>
+              <SelectTrigger id="profile" aria-controls='profile'>
+                <SelectValue placeholder="Select Profile" />
+              </SelectTrigger>
+              <SelectContent position="popper">
+                {profiles.map(p => <SelectItem key={p} value={p}>{p === active ? `${p} (active)` : p}</SelectItem>)}
+              </SelectContent>
+            </Select>
+          </div>
+          <div className='flex space-x-4'>
+            <Button type='button' disabled={!selected || selected === active} onClick={switchProfile}>Switch</Button>
+            <Button type='button' variant='destructive' disabled={!selected || selected === active} onClick={deleteProfile}>Delete</Button>
+            <Button type='button' variant='outline' onClick={importProfiles}>Import</Button>
+            <Button type='button' variant='outline' onClick={exportProfiles}>Export</Button>
+          </div>
+          <div className="flex flex-col space-y-1.5">
+            <Label htmlFor='profile-name'>New Profile</Label>
+            <Input id='profile-name' placeholder='UAT' value={name} onChange={e => setName(e.target.value)}/>
+          </div>
+          <div className='flex space-x-4'>
+            <Button type='button' variant='outline' disabled={!name} onClick={createProfile}>Copy Active Profile</Button>
+          </div>
+        </div>
+      </CardContent>
+    </Card>
+  )
+}
+
+export default Profiles
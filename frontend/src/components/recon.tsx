@@ -0,0 +1,309 @@
+import { ColumnDef } from '@tanstack/react-table'
+import { Fragment, useEffect, useState } from 'react'
+import { useRecoilState } from 'recoil'
+import { ExportReconReport, GetReconMapping, GetReconciliationTotals, ImportSettlement, SaveReconMapping, SendReconciliation } from '../../wailsjs/go/main/App'
+import { main } from '../../wailsjs/go/models'
+import { loadingState } from '@/store/state'
+import { DataTable } from './data-table'
+import { Button } from './ui/button'
+import { Input } from './ui/input'
+import { Label } from './ui/label'
+import {
+  Select,
+  SelectContent,
+  SelectItem,
+  SelectTrigger,
+  SelectValue
+} from './ui/select'
+import { useToast } from './ui/use-toast'
+import {
+  Card,
+  CardContent,
+  CardHeader,
+  CardTitle
+} from '@/components/ui/card'
+
+const columnKeys = [['rrn', 'RRN'], ['stan', 'STAN'], ['terminalId', 'Terminal ID'], ['amount', 'Amount']] as const
+
+const totalKeys = [
+  ['debitsNumber', 'Debits'],
+  ['debitsAmount', 'Debits Amount'],
+  ['debitsReversalNumber', 'Debit Reversals'],
+  ['debitsReversalAmount', 'Debit Reversals Amount'],
+  ['creditsNumber', 'Credits'],
+  ['creditsAmount', 'Credits Amount'],
+  ['creditsReversalNumber', 'Credit Reversals'],
+  ['creditsReversalAmount', 'Credit Reversals Amount'],
+  ['netAmount', 'Net Amount'],
+] as const
+
+export function Recon () {
+  const columns: ColumnDef<main.ReconEntry>[] = [
+    {
+      accessorKey: 'status',
+      header: () => <div className="text-center">Status</div>,
+    },
+    {
+      accessorKey: 'line',
+      header: () => <div className="text-center">Line</div>,
+    },
+    {
+      accessorKey: 'messageId',
+      header: () => <div className="text-center">Message ID</div>,
+    },
+    {
+      accessorKey: 'rrn',
+      header: () => <div className="text-center">RRN</div>,
+    },
+    {
+      accessorKey: 'stan',
+      header: () => <div className="text-center">STAN</div>,
+    },
+    {
+      accessorKey: 'terminalId',
+      header: () => <div className="text-center">Terminal ID</div>,
+    },
+    {
+      accessorKey: 'fileAmount',
+      header: () => <div className="text-center">File Amount</div>,
+    },
+    {
+      accessorKey: 'messageAmount',
+      header: () => <div className="text-center">Sent Amount</div>,
+    },
+  ]
+
+  const today = new Date().toISOString().slice(0, 10)
+  const [, setLoading] = useRecoilState(loadingState)
+  const [atmSwitch, setAtmSwitch] = useState('CORTEX')
+  const [mapping, setMapping] = useState<main.ReconMapping>()
+  const [from, setFrom] = useState(today)
+  const [to, setTo] = useState(today)
+  const [report, setReport] = useState<main.ReconReport>()
+  const [cutOffFrom, setCutOffFrom] = useState(`${today}T00:00`)
+  const [cutOffTo, setCutOffTo] = useState(`${today}T23:59`)
+  const [currencyCode, setCurrencyCode] = useState('608')
+  const [acquirer, setAcquirer] = useState('')
+  const [advice, setAdvice] = useState(false)
+  const [totals, setTotals] = useState<main.ReconciliationTotals>()
+  const [result, setResult] = useState<main.ReconciliationResult>()
+  const { toast } = useToast()
+
+  const run = async (action: () => Promise<void>) => {
+    try {
+      setLoading(true)
+      await action()
+    } catch(error: any) {
+      toast({
+        description: error,
+      })
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  const update = (key: string, value: string | number | boolean) => {
+    setMapping(current => main.ReconMapping.createFrom({ ...current, [key]: value }))
+  }
+
+  const saveMapping = () => run(async () => {
+    await SaveReconMapping(mapping!)
+    toast({
+      description: 'Mapping has been saved.',
+    })
+  })
+
+  const importFile = () => run(async () => {
+    const result = await ImportSettlement(atmSwitch, from, to)
+    if(result.file) {
+      setReport(result)
+    }
+  })
+
+  const exportReport = () => run(async () => {
+    const path = await ExportReconReport(report!)
+    if(path) {
+      toast({
+        description: `Report saved to ${path}.`,
+      })
+    }
+  })
+
+  const reconciliationRequest = () => main.ReconciliationRequest.createFrom({
+    switch: atmSwitch,
+    from: cutOffFrom,
+    to: cutOffTo,
+    currencyCode,
+    acquiringInstitutionCode: acquirer,
+    advice,
+  })
+
+  const previewTotals = () => run(async () => {
+    setResult(undefined)
+    setTotals(await GetReconciliationTotals(reconciliationRequest()))
+  })
+
+  const sendTotals = () => run(async () => {
+    const response = await SendReconciliation(reconciliationRequest())
+    setTotals(response.local)
+    setResult(response)
+  })
+
+  useEffect(() => {
+    GetReconMapping(atmSwitch).then(setMapping).catch((error: any) => {
+      toast({
+        description: error,
+      })
+    })
+  }, [atmSwitch])
+
+  return (
+    <div className='flex flex-col w-full space-y-10'>
+      <Card>
+        <CardHeader>
+          <CardTitle className="text-center">Settlement File</CardTitle>
+        </CardHeader>
+        <CardContent className='space-y-4'>
+          <div className='flex items-end space-x-4'>
+            <div className='flex flex-col space-y-1.5'>
+              <Label htmlFor='recon-switch'>Switch</Label>
+              <Select onValueChange={setAtmSwitch} defaultValue={atmSwitch}>
+                <SelectTrigger id='recon-switch' className='w-48'>
+                  <SelectValue placeholder="Select Switch"/>
+                </SelectTrigger>
+                <SelectContent position="popper">
+                  <SelectItem value="CORTEX">Cortex</SelectItem>
+                  <SelectItem value="NARADA">Narada</SelectItem>
+                  <SelectItem value="POSTBRIDGE">PostBridge</SelectItem>
+                </SelectContent>
+              </Select>
+            </div>
+            {mapping && (
+              <div className='flex flex-col space-y-1.5'>
+                <Label htmlFor='recon-format'>Format</Label>
+                <Select key={atmSwitch} onValueChange={v => update('format', v)} defaultValue={mapping.format}>
+                  <SelectTrigger id='recon-format' className='w-48'>
+                    <SelectValue placeholder="Select Format"/>
+                  </SelectTrigger>
+                  <SelectContent position="popper">
+                    <SelectItem value="CSV">CSV</SelectItem>
+                    <SelectItem value="FIXED_WIDTH">Fixed Width</SelectItem>
+                  </SelectContent>
+                </Select>
+              </div>
+            )}
+          </div>
+          {mapping && (
+            <div className='grid grid-cols-4 gap-4'>
+              <div className='flex flex-col space-y-1.5'>
+                <Label htmlFor='recon-delimiter'>Delimiter</Label>
+                <Input id='recon-delimiter' value={mapping.delimiter} disabled={mapping.format !== 'CSV'} onChange={e => update('delimiter', e.target.value)}/>
+              </div>
+              <div className='flex flex-col space-y-1.5'>
+                <Label htmlFor='recon-header'>Header Lines</Label>
+                <Input id='recon-header' value={mapping.headerLines} onChange={e => update('headerLines', Number(e.target.value) || 0)}/>
+              </div>
+              <div className='flex flex-col space-y-1.5'>
+                <Label htmlFor='recon-footer'>Footer Lines</Label>
+                <Input id='recon-footer' value={mapping.footerLines} onChange={e => update('footerLines', Number(e.target.value) || 0)}/>
+              </div>
+              <div className='flex items-center space-x-2 pt-6'>
+                <input id='recon-minor' type='checkbox' checked={mapping.minorUnits} onChange={e => update('minorUnits', e.target.checked)}/>
+                <Label htmlFor='recon-minor'>Amount in minor units</Label>
+              </div>
+              {columnKeys.map(([k, label]) => (
+                <div key={k} className='flex flex-col space-y-1.5'>
+                  <Label htmlFor={`recon-${k}`}>{label} {mapping.format === 'CSV' ? 'Column' : 'Positions'}</Label>
+                  <Input id={`recon-${k}`} placeholder={mapping.format === 'CSV' ? '1' : '1-12'} value={mapping[k]} onChange={e => update(k, e.target.value)}/>
+                </div>
+              ))}
+            </div>
+          )}
+          <div className='flex items-end justify-end space-x-4'>
+            <div className='flex flex-col space-y-1.5'>
+              <Label htmlFor='recon-from'>From</Label>
+              <Input id='recon-from' type='date' value={from} onChange={e => setFrom(e.target.value)}/>
+            </div>
+            <div className='flex flex-col space-y-1.5'>
+              <Label htmlFor='recon-to'>To</Label>
+              <Input id='recon-to' type='date' value={to} onChange={e => setTo(e.target.value)}/>
+            </div>
+            <Button variant='outline' onClick={saveMapping} disabled={!mapping}>Save Mapping</Button>
+            <Button onClick={importFile}>Import and Reconcile</Button>
+          </div>
+        </CardContent>
+      </Card>
+
+      <Card>
+        <CardHeader>
+          <CardTitle className="text-center">Reconciliation Totals</CardTitle>
+        </CardHeader>
+        <CardContent className='space-y-4'>
+          <div className='flex items-end space-x-4'>
+            <div className='flex flex-col space-y-1.5'>
+              <Label htmlFor='totals-from'>Cut-off From</Label>
+              <Input id='totals-from' type='datetime-local' value={cutOffFrom} onChange={e => setCutOffFrom(e.target.value)}/>
+            </div>
+            <div className='flex flex-col space-y-1.5'>
+              <Label htmlFor='totals-to'>Cut-off To</Label>
+              <Input id='totals-to' type='datetime-local' value={cutOffTo} onChange={e => setCutOffTo(e.target.value)}/>
+            </div>
+            <div className='flex flex-col space-y-1.5'>
+              <Label htmlFor='totals-currency'>Currency</Label>
+              <Input id='totals-currency' value={currencyCode} onChange={e => setCurrencyCode(e.target.value)}/>
+            </div>
+            <div className='flex flex-col space-y-1.5'>
+              <Label htmlFor='totals-acquirer'>Acquirer</Label>
+              <Input id='totals-acquirer' placeholder='All' value={acquirer} onChange={e => setAcquirer(e.target.value)}/>
+            </div>
+            <div className='flex items-center space-x-2 pb-2'>
+              <input id='totals-advice' type='checkbox' checked={advice} onChange={e => setAdvice(e.target.checked)}/>
+              <Label htmlFor='totals-advice'>Advice</Label>
+            </div>
+          </div>
+          <div className='flex justify-end space-x-4'>
+            <Button variant='outline' onClick={previewTotals}>Compute</Button>
+            <Button onClick={sendTotals}>Send to {atmSwitch}</Button>
+          </div>
+          {totals && (
+            <div className='grid grid-cols-3 gap-2 text-sm'>
+              <span></span><span>Local</span><span>Host</span>
+              {totalKeys.map(([k, label]) => (
+                <Fragment key={k}>
+                  <span>{label}</span>
+                  <span>{totals[k]}</span>
+                  <span>{result?.hostTotals ? result.host[k] : '-'}</span>
+                </Fragment>
+              ))}
+            </div>
+          )}
+          {result && (
+            <div className='text-sm space-y-1'>
+              <div>{result.response.mti} response code {result.responseCode || '-'}, settlement code {result.settlementCode || '-'}</div>
+              {!result.hostTotals && <div>The host did not return totals.</div>}
+              {result.hostTotals && result.differences.length === 0 && <div>Host totals match.</div>}
+              {result.differences.map(d => <div key={d}>{d}</div>)}
+            </div>
+          )}
+        </CardContent>
+      </Card>
+
+      {report && (
+        <Card>
+          <CardHeader>
+            <CardTitle className="text-center">Reconciliation</CardTitle>
+          </CardHeader>
+          <CardContent className='space-y-4'>
+            <div className='text-sm'>
+              {report.switch} {report.file} from {report.from} to {report.to}: {report.matched} matched, {report.mismatched} amount mismatched, {report.missing} missing from the file, {report.unexpected} unexpected
+            </div>
+            <DataTable columns={columns} data={report.entries} />
+            <div className='flex justify-end'>
+              <Button onClick={exportReport}>Export CSV</Button>
+            </div>
+          </CardContent>
+        </Card>
+      )}
+    </div>
+  )
+}
@@ -0,0 +1,84 @@
+import { useEffect, useState } from 'react'
+import { AlertDialog, AlertDialogAction, AlertDialogCancel, AlertDialogContent, AlertDialogDescription, AlertDialogFooter, AlertDialogHeader, AlertDialogTitle } from './ui/alert-dialog'
+import { Input } from './ui/input'
+import { Label } from './ui/label'
+import {
+  Select,
+  SelectContent,
+  SelectItem,
+  SelectTrigger,
+  SelectValue
+} from './ui/select'
+import { main } from '../../wailsjs/go/models'
+
+const reasons = [
+  ['', 'Default'],
+  ['17', '17 Customer Cancellation'],
+  ['21', '21 Unable to Deliver'],
+  ['22', '22 Suspected Malfunction'],
+  ['32', '32 Partial Dispense'],
+  ['68', '68 Response Timeout'],
+] as const
+
+type Props = {
+  message?: main.Message
+  title: string
+  isOpen: boolean
+  setOpen: (isOpen: boolean) => void
+  onConfirm: (options: main.ReversalOptions) => void
+}
+
+const ReversalDialog = ({message, title, isOpen, setOpen, onConfirm}: Props) => {
+  const [dispensedAmount, setDispensedAmount] = useState('0')
+  const [reason, setReason] = useState('')
+
+  useEffect(() => {
+    setDispensedAmount('0')
+    setReason('')
+  }, [message])
+
+  const confirm = () => {
+    onConfirm(main.ReversalOptions.createFrom({
+      dispensedAmount: Number(dispensedAmount) || 0,
+      reason: reason,
+    }))
+  }
+
+  return (
+    <AlertDialog open={isOpen} onOpenChange={() => setOpen(!isOpen)}>
+      <AlertDialogContent>
+        <AlertDialogHeader>
+          <AlertDialogTitle>{title}</AlertDialogTitle>
+        </AlertDialogHeader>
+        <AlertDialogDescription>
+          {message?.transaction} {message?.traceNumber} of {message?.transactionAmount}. Enter the dispensed amount for a partial reversal.
+        </AlertDialogDescription>
+        <div className='flex flex-col space-y-4'>
+          <div className='flex flex-col space-y-1.5'>
+            <Label htmlFor='reversal-dispensed'>Dispensed Amount</Label>
+            <Input id='reversal-dispensed' value={dispensedAmount} onChange={e => setDispensedAmount(e.target.value)}/>
+          </div>
+          <div className='flex flex-col space-y-1.5'>
+            <Label htmlFor='reversal-reason'>Reason</Label>
+            <Select key={message?.id} onValueChange={v => setReason(v === 'DEFAULT' ? '' : v)} defaultValue='DEFAULT'>
+              <SelectTrigger id='reversal-reason'>
+                <SelectValue placeholder="Select Reason"/>
+              </SelectTrigger>
+              <SelectContent position="popper">
+                {reasons.map(([value, label]) => (
+                  <SelectItem key={label} value={value || 'DEFAULT'}>{label}</SelectItem>
+                ))}
+              </SelectContent>
+            </Select>
+          </div>
+        </div>
+        <AlertDialogFooter>
+          <AlertDialogCancel>Cancel</AlertDialogCancel>
+          <AlertDialogAction onClick={confirm}>Send</AlertDialogAction>
+        </AlertDialogFooter>
+      </AlertDialogContent>
+    </AlertDialog>
+  )
+}
+
+export default ReversalDialog
@@ -0,0 +1,117 @@
+import { ColumnDef } from '@tanstack/react-table'
+import { useEffect, useState } from 'react'
+import { useRecoilState } from 'recoil'
+import { GetSafItems, PurgeSaf, ResendSafItem } from '../../wailsjs/go/main/App'
+import { main } from '../../wailsjs/go/models'
+import { EventsOff, EventsOn } from '../../wailsjs/runtime'
+import { loadingState } from '@/store/state'
+import { DataTable } from './data-table'
+import { Button } from './ui/button'
+import { useToast } from './ui/use-toast'
+import {
+  Card,
+  CardContent,
+  CardHeader,
+  CardTitle
+} from '@/components/ui/card'
+
+export function Saf () {
+  const columns: ColumnDef<main.SafItem>[] = [
+    {
+      accessorKey: 'id',
+      header: () => <div className="text-center">ID</div>,
+    },
+    {
+      accessorKey: 'switch',
+      header: () => <div className="text-center">Switch</div>,
+    },
+    {
+      accessorKey: 'mti',
+      header: () => <div className="text-center">MTI</div>,
+    },
+    {
+      accessorKey: 'transaction',
+      header: () => <div className="text-center">Transaction</div>,
+    },
+    {
+      accessorKey: 'traceNumber',
+      header: () => <div className="text-center">Trace Number</div>,
+    },
+    {
+      accessorKey: 'amount',
+      header: () => <div className="text-center">Amount</div>,
+    },
+    {
+      accessorKey: 'status',
+      header: () => <div className="text-center">Status</div>,
+      cell: ({row}) => `${row.original.status} (${row.original.attempts} attempts)`,
+    },
+    {
+      accessorKey: 'lastError',
+      header: () => <div className="text-center">Last Error</div>,
+      cell: ({row}) => <div className='text-xs break-all'>{row.original.lastAttemptAt} {row.original.lastError}</div>,
+    },
+    {
+      accessorKey: 'action',
+      header: () => <div className="text-center">Action</div>,
+      cell: ({row}) => (
+        <div className='flex justify-center space-x-5'>
+          <Button onClick={() => resend(row.original.id)}>Resend</Button>
+          <Button variant="destructive" onClick={() => purge(row.original.id)}>Purge</Button>
+        </div>
+      )
+    },
+  ]
+
+  const [, setLoading] = useRecoilState(loadingState)
+  const [items, setItems] = useState<main.SafItem[]>([])
+  const { toast } = useToast()
+
+  const run = async (action: () => Promise<void>) => {
+    try {
+      setLoading(true)
+      await action()
+    } catch(error: any) {
+      toast({
+        description: error,
+      })
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  const resend = (id: number) => run(async () => {
+    await ResendSafItem(id)
+    toast({
+      description: `Advice ${id} has been acknowledged.`,
+    })
+  })
+
+  const purge = (id: number) => run(async () => PurgeSaf(id))
+
+  useEffect(() => {
+    GetSafItems().then(setItems).catch((error: any) => {
+      toast({
+        description: error,
+      })
+    })
+    EventsOn('saf', setItems)
+    return () => {
+      EventsOff('saf')
+    }
+  }, [])
+
+  return (
+    <Card className='w-full'>
+      <CardHeader>
+        <CardTitle className="text-center">Store and Forward</CardTitle>
+      </CardHeader>
+      <CardContent className='space-y-4'>
+        <DataTable columns={columns} data={items} />
+        <div className='flex justify-end'>
+          <Button variant='destructive' disabled={items.length === 0} onClick={() => purge(0)}>Purge All</Button>
+        </div>
+      </CardContent>
+    </Card>
+  )
+}
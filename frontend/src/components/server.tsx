@@ -0,0 +1,240 @@
+import { ColumnDef } from '@tanstack/react-table'
+import { Fragment, useEffect, useState } from 'react'
+import { useRecoilState } from 'recoil'
+import { AnswerServerRequest, CloseLinks, GetLinks, GetServerRules, GetServerStatus, SaveServerRules, StartServer, StopServer } from '../../wailsjs/go/main/App'
+import { main } from '../../wailsjs/go/models'
+import { EventsOff, EventsOn } from '../../wailsjs/runtime'
+import { loadingState } from '@/store/state'
+import { LinkMessage, ServerMessage } from '@/lib/message'
+import { DataTable } from './data-table'
+import { Button } from './ui/button'
+import { Input } from './ui/input'
+import { Label } from './ui/label'
+import {
+  Select,
+  SelectContent,
+  SelectItem,
+  SelectTrigger,
+  SelectValue
+} from './ui/select'
+import { useToast } from './ui/use-toast'
+import {
+  Card,
+  CardContent,
+  CardHeader,
+  CardTitle
+} from '@/components/ui/card'
+
+const ruleKeys = ['mti', 'processCode', 'panPrefix', 'responseCode', 'balance'] as const
+
+export function Server () {
+  const columns: ColumnDef<ServerMessage>[] = [
+    {
+      accessorKey: 'time',
+      header: () => <div className="text-center">Time</div>,
+    },
+    {
+      accessorKey: 'remote',
+      header: () => <div className="text-center">Remote</div>,
+    },
+    {
+      accessorKey: 'inbound',
+      header: () => <div className="text-center">Direction</div>,
+      cell: ({row}) => row.original.inbound ? 'IN' : 'OUT',
+    },
+    {
+      accessorKey: 'message',
+      header: () => <div className="text-center">Message</div>,
+      cell: ({row}) => {
+        const m = row.original.message
+        return (
+          <div className='font-mono text-xs break-all'>
+            <div>{m.mti}{row.original.error ? ` ${row.original.error}` : ''}</div>
+            {Object.entries(m.fields ?? {}).map(([k, v]) => <div key={k}>{k}: {v}</div>)}
+          </div>
+        )
+      }
+    },
+  ]
+
+  const [, setLoading] = useRecoilState(loadingState)
+  const [status, setStatus] = useState<main.ServerStatus>()
+  const [atmSwitch, setAtmSwitch] = useState('CORTEX')
+  const [port, setPort] = useState('50122')
+  const [interactive, setInteractive] = useState(false)
+  const [rules, setRules] = useState<main.ServerRule[]>([])
+  const [messages, setMessages] = useState<ServerMessage[]>([])
+  const [requests, setRequests] = useState<ServerMessage[]>([])
+  const [answers, setAnswers] = useState<Record<number, string>>({})
+  const [links, setLinks] = useState<main.LinkStatus[]>([])
+  const [linkMessages, setLinkMessages] = useState<LinkMessage[]>([])
+  const { toast } = useToast()
+
+  const run = async (action: () => Promise<void>) => {
+    try {
+      setLoading(true)
+      await action()
+    } catch(error: any) {
+      toast({
+        description: error,
+      })
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  const toggle = () => run(async () => {
+    if(status?.running) {
+      await StopServer()
+    } else {
+      await StartServer(atmSwitch, Number(port), interactive)
+    }
+    setStatus(await GetServerStatus())
+  })
+
+  const saveRules = () => run(async () => {
+    await SaveServerRules(rules)
+    setRules(await GetServerRules())
+    toast({
+      description: 'Rules have been saved.',
+    })
+  })
+
+  const answer = (id: number, responseCode: string) => run(async () => {
+    await AnswerServerRequest(id, responseCode)
+    setRequests(current => current.filter(r => r.id !== id))
+  })
+
+  const updateRule = (i: number, key: string, value: string | number) => {
+    setRules(current => current.map((r, j) => j === i ? main.ServerRule.createFrom({ ...r, [key]: value }) : r))
+  }
+
+  useEffect(() => {
+    GetServerStatus().then(setStatus)
+    GetServerRules().then(setRules).catch((error: any) => {
+      toast({
+        description: error,
+      })
+    })
+    EventsOn('server:message', (message: ServerMessage) => {
+      setMessages(current => [message, ...current].slice(0, 200))
+    })
+    EventsOn('server:request', (request: ServerMessage) => {
+      setRequests(current => [...current, request])
+    })
+    GetLinks().then(setLinks)
+    EventsOn('link', setLinks)
+    EventsOn('link:message', (message: LinkMessage) => {
+      setLinkMessages(current => [message, ...current].slice(0, 200))
+    })
+    return () => {
+      EventsOff('server:message')
+      EventsOff('server:request')
+      EventsOff('link')
+      EventsOff('link:message')
+    }
+  }, [])
+
+  return (
+    <div className='flex flex-col w-full space-y-10'>
+      <Card>
+        <CardHeader>
+          <CardTitle className="text-center">Server</CardTitle>
+        </CardHeader>
+        <CardContent>
+          <div className='flex items-end space-x-4'>
+            <div className='flex flex-col space-y-1.5'>
+              <Label htmlFor='server-switch'>Switch</Label>
+              <Select onValueChange={setAtmSwitch} defaultValue={atmSwitch} disabled={status?.running}>
+                <SelectTrigger id='server-switch' className='w-48'>
+                  <SelectValue placeholder="Select Switch"/>
+                </SelectTrigger>
+                <SelectContent position="popper">
+                  <SelectItem value="CORTEX">Cortex</SelectItem>
+                  <SelectItem value="NARADA">Narada</SelectItem>
+                  <SelectItem value="POSTBRIDGE">PostBridge</SelectItem>
+                </SelectContent>
+              </Select>
+            </div>
+            <div className='flex flex-col space-y-1.5'>
+              <Label htmlFor='server-port'>Port</Label>
+              <Input id='server-port' value={port} onChange={e => setPort(e.target.value)} disabled={status?.running}/>
+            </div>
+            <div className='flex items-center space-x-2 pb-2'>
+              <input id='server-interactive' type='checkbox' checked={interactive} onChange={e => setInteractive(e.target.checked)} disabled={status?.running}/>
+              <Label htmlFor='server-interactive'>Ask when no rule matches</Label>
+            </div>
+            <Button onClick={toggle} variant={status?.running ? 'destructive' : 'default'}>
+              {status?.running ? `Stop ${status.switch} on ${status.port}` : 'Start'}
+            </Button>
+          </div>
+        </CardContent>
+      </Card>
+
+      {requests.map(r => (
+        <Card key={r.id}>
+          <CardHeader>
+            <CardTitle>Request {r.id} from {r.remote}: {r.message.mti} {r.message.fields?.['3']} {r.message.fields?.['4']}</CardTitle>
+          </CardHeader>
+          <CardContent className='flex items-end space-x-4'>
+            <Input placeholder='Response code' value={answers[r.id] ?? ''} onChange={e => setAnswers({ ...answers, [r.id]: e.target.value })}/>
+            <Button onClick={() => answer(r.id, answers[r.id] ?? '')} disabled={!answers[r.id]}>Reply</Button>
+            <Button variant='destructive' onClick={() => answer(r.id, '')}>Drop</Button>
+          </CardContent>
+        </Card>
+      ))}
+
+      <Card>
+        <CardHeader>
+          <CardTitle className="text-center">Rules</CardTitle>
+        </CardHeader>
+        <CardContent className='space-y-4'>
+          <div className='grid grid-cols-7 gap-2 text-sm'>
+            <span>MTI</span><span>Processing Code</span><span>PAN Prefix</span><span>Response Code</span><span>Field 54</span><span>Delay (ms)</span><span></span>
+            {rules.map((r, i) => (
+              <Fragment key={i}>
+                {ruleKeys.map(k => <Input key={`${i}-${k}`} value={r[k]} onChange={e => updateRule(i, k, e.target.value)}/>)}
+                <Input key={`${i}-delay`} value={r.delay} onChange={e => updateRule(i, 'delay', Number(e.target.value) || 0)}/>
+                <Button key={`${i}-remove`} variant='destructive' onClick={() => setRules(rules.filter((_, j) => j !== i))}>Remove</Button>
+              </Fragment>
+            ))}
+          </div>
+          <div className='flex justify-end space-x-4'>
+            <Button variant='outline' onClick={() => setRules([...rules, main.ServerRule.createFrom({ mti: '', processCode: '', panPrefix: '', responseCode: '00', balance: '', delay: 0 })])}>Add Rule</Button>
+            <Button onClick={saveRules}>Save Rules</Button>
+          </div>
+        </CardContent>
+      </Card>
+
+      <Card>
+        <CardHeader>
+          <CardTitle className="text-center">Host Links</CardTitle>
+        </CardHeader>
+        <CardContent className='space-y-4'>
+          {links.length === 0 && <div className='text-sm'>No persistent link is open, set SWITCH_PERSISTENT in the settings to keep one.</div>}
+          {links.map(l => <div key={l.switch} className='text-sm'>{l.switch} connected to {l.remote}, {l.pending} outstanding requests</div>)}
+          <div className='font-mono text-xs break-all space-y-2'>
+            {linkMessages.map(m => (
+              <div key={m.id}>
+                {m.time} {m.switch} {m.inbound ? 'IN unsolicited' : 'OUT auto reply'} {m.message.mti}{m.error ? ` ${m.error}` : ''}
+                {' '}{Object.entries(m.message.fields ?? {}).map(([k, v]) => `${k}=${v}`).join(' ')}
+              </div>
+            ))}
+          </div>
+          <div className='flex justify-end'>
+            <Button variant='destructive' disabled={links.length === 0} onClick={() => run(async () => CloseLinks())}>Close Links</Button>
+          </div>
+        </CardContent>
+      </Card>
+
+      <Card>
+        <CardHeader>
+          <CardTitle className="text-center">Messages</CardTitle>
+        </CardHeader>
+        <CardContent>
+          <DataTable columns={columns} data={messages} />
+        </CardContent>
+      </Card>
+    </div>
+  )
+}
@@ -14,9 +14,12 @@ import { Form, FormControl, FormField, FormItem, FormLabel, FormMessage } from '
 import { useEffect, useState } from 'react'
 import { useRecoilState } from 'recoil'
 import { loadingState } from '@/store/state'
-import { GetConfigs, UpdateConfigs, OpenFileDialog} from '../../wailsjs/go/main/App'
+import { GetConfigs, UpdateConfigs, OpenFileDialog, TestConnection} from '../../wailsjs/go/main/App'
 import { main } from '../../wailsjs/go/models'
 import { useToast } from '@/components/ui/use-toast'
+import Profiles from './profiles'
+import Vault from './vault'
+import KnownHosts from './known-hosts'
 
 type Props = {}
 
@@ -55,7 +58,7 @@ const Settings = (props: Props) => {
     }
   }
 
-  useEffect(() => {
+  const loadConfigs = () => {
     GetConfigs().then(_configs => {
       setConfigs(_configs)
       form.reset({
@@ -66,7 +69,9 @@ const Settings = (props: Props) => {
         description: error,
       })
     })
-  }, [])
+  }
+
+  useEffect(loadConfigs, [])
 
   
   const openFileDialog = async (event: React.MouseEvent<HTMLElement>, i: number) => {
@@ -82,43 +87,70 @@ const Settings = (props: Props) => {
     }
   }
 
+  const testConnection = async (atmSwitch: string) => {
+    try {
+      setLoading(true)
+      const test = await TestConnection(atmSwitch)
+      const chain = (test.certificates ?? []).map(c => `${c.subject} (${c.pin})`).join(' <- ')
+      toast({
+        description: test.tls
+          ? `${atmSwitch} ${test.address}: ${test.version} ${test.cipherSuite}, server name ${test.serverName}. Chain: ${chain}`
+          : `${atmSwitch} ${test.address}: connected without TLS.`,
+      })
+    } catch(error: any) {
+      toast({
+        description: error,
+      })
+    } finally {
+      setLoading(false)
+    }
+  }
+
   return (
-    <Form {...form}>
-      <form onSubmit={form.handleSubmit(onSubmit)} className="w-full">
-        <Card>
-          <CardHeader>
-            <CardTitle className="text-center">Settings</CardTitle>
-          </CardHeader>
-          <CardContent>
-            <div className="grid w-full items-start gap-x-10 gap-y-4 sm:grid-cols-2">
-              {configs.map((c, i) => {
-                return (
-                  <div className="flex flex-col space-y-1.5" key={c.key}>
-                    <FormField
-                      control={form.control}
-                      name={`configs.${i}.value` as const}
-                      render={({ field }) => (
-                        <FormItem>
-                          <FormLabel htmlFor={c.key}>{c.key}</FormLabel>
-                          <FormControl>
-                            <Input  {...form.register(`configs.${i}.value`)} onClick={(e) => c.key === 'SSH_KEY' ? openFileDialog(e, i): undefined} type={c.key === 'SSH_PASSPHRASE' ? 'password' : 'text'} id={c.key} aria-describedby={c.key} defaultValue={field.value ?? ''} onChange={field.onChange}/>
-                          </FormControl>
-                          <FormMessage />
-                        </FormItem>
-                      )}
-                    />
-                  </div>
-                )
-              })}
-            </div>
-          </CardContent>
-          <hr className='my-10 mx-10 h-1 rounded bg-slate-700' />
-          <CardFooter className="flex justify-end">
-            <Button type='submit'>Submit</Button>
-          </CardFooter>
-        </Card>
-      </form>
-    </Form>
+    <div className='w-full'>
+      <Vault onChange={loadConfigs}/>
+      <Profiles onChange={loadConfigs}/>
+      <Form {...form}>
+        <form onSubmit={form.handleSubmit(onSubmit)} className="w-full">
+          <Card>
+            <CardHeader>
+              <CardTitle className="text-center">Settings</CardTitle>
+            </CardHeader>
+            <CardContent>
+              <div className="grid w-full items-start gap-x-10 gap-y-4 sm:grid-cols-2">
+                {configs.map((c, i) => {
+                  return (
+                    <div className="flex flex-col space-y-1.5" key={c.key}>
+                      <FormField
+                        control={form.control}
+                        name={`configs.${i}.value` as const}
+                        render={({ field }) => (
+                          <FormItem>
+                            <FormLabel htmlFor={c.key}>{c.key}</FormLabel>
+                            <FormControl>
+                              <Input  {...form.register(`configs.${i}.value`)} onClick={(e) => (['SSH_KEY', 'SSH_CERTIFICATE'].includes(c.key) || /_TLS_(CA|CERT|KEY)$/.test(c.key)) ? openFileDialog(e, i): undefined} type={(['SSH_PASSPHRASE', 'SSH_PRIVATE_KEY', 'SSH_PASSWORD', 'PROXY_PASSWORD'].includes(c.key) || c.key.endsWith('_ZPK')) ? 'password' : 'text'} id={c.key} aria-describedby={c.key} defaultValue={field.value ?? ''} onChange={field.onChange}/>
+                            </FormControl>
+                            <FormMessage />
+                          </FormItem>
+                        )}
+                      />
+                    </div>
+                  )
+                })}
+              </div>
+            </CardContent>
+            <hr className='my-10 mx-10 h-1 rounded bg-slate-700' />
+            <CardFooter className="flex justify-end space-x-4">
+              {['CORTEX', 'NARADA', 'POSTBRIDGE'].map(atmSwitch => (
+                <Button type='button' variant='outline' key={atmSwitch} onClick={() => testConnection(atmSwitch)}>Test {atmSwitch}</Button>
+              ))}
+              <Button type='submit'>Submit</Button>
+            </CardFooter>
+          </Card>
+        </form>
+      </Form>
+      <KnownHosts />
+    </div>
   )
 }
 
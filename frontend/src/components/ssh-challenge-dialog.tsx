@@ -0,0 +1,71 @@
+import { useEffect, useState } from 'react'
+import { AlertDialog, AlertDialogAction, AlertDialogCancel, AlertDialogContent, AlertDialogDescription, AlertDialogFooter, AlertDialogHeader, AlertDialogTitle } from './ui/alert-dialog'
+import { Input } from './ui/input'
+import { Label } from './ui/label'
+import { AnswerSshChallenge } from '../../wailsjs/go/main/App'
+import { EventsOff, EventsOn } from '../../wailsjs/runtime'
+import { SshChallenge } from '@/lib/message'
+import { useToast } from './ui/use-toast'
+
+// SshChallengeDialog answers the password and keyboard-interactive questions,
+// such as an OTP, of the bastion.
+const SshChallengeDialog = () => {
+
+  const [challenge, setChallenge] = useState<SshChallenge>()
+  const [answers, setAnswers] = useState<string[]>([])
+  const { toast } = useToast()
+
+  useEffect(() => {
+    EventsOn('ssh:challenge', (c: SshChallenge) => {
+      setAnswers(c.questions.map(() => ''))
+      setChallenge(c)
+    })
+    return () => {
+      EventsOff('ssh:challenge')
+    }
+  }, [])
+
+  const respond = async (submitted: string[]) => {
+    setChallenge(undefined)
+    setAnswers([])
+    try {
+      await AnswerSshChallenge(submitted)
+    } catch(error: any) {
+      toast({
+        description: error,
+      })
+    }
+  }
+
+  return (
+    <AlertDialog open={!!challenge}>
+      <AlertDialogContent>
+        <AlertDialogHeader>
+          <AlertDialogTitle>SSH Authentication{challenge?.user ? ` for ${challenge.user}` : ''}</AlertDialogTitle>
+        </AlertDialogHeader>
+        <AlertDialogDescription>
+          <form className='flex flex-col w-full space-y-4' onSubmit={e => {
+            e.preventDefault()
+            respond(answers)
+          }}>
+            {challenge?.instruction && <span>{challenge.instruction}</span>}
+            {challenge?.questions.map((q, i) => (
+              <div className='flex flex-col space-y-1.5' key={i}>
+                <Label htmlFor={`ssh-answer-${i}`}>{q}</Label>
+                <Input id={`ssh-answer-${i}`} autoFocus={i === 0} type={challenge.echos[i] ? 'text' : 'password'} value={answers[i] ?? ''}
+                  onChange={e => setAnswers(current => current.map((a, j) => j === i ? e.target.value : a))}/>
+              </div>
+            ))}
+            <button type='submit' hidden/>
+          </form>
+        </AlertDialogDescription>
+        <AlertDialogFooter>
+          <AlertDialogCancel onClick={() => respond([])}>Cancel</AlertDialogCancel>
+          <AlertDialogAction onClick={() => respond(answers)}>Submit</AlertDialogAction>
+        </AlertDialogFooter>
+      </AlertDialogContent>
+    </AlertDialog>
+  )
+}
+
+export default SshChallengeDialog
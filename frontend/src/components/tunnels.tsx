@@ -0,0 +1,180 @@
+import { ColumnDef } from '@tanstack/react-table'
+import { useEffect, useState } from 'react'
+import { useRecoilState } from 'recoil'
+import { DeleteTunnel, GetTunnels, SaveTunnel, StartTunnel, StopTunnel } from '../../wailsjs/go/main/App'
+import { main } from '../../wailsjs/go/models'
+import { loadingState, tunnelStatusState } from '@/store/state'
+import { DataTable } from './data-table'
+import { Button } from './ui/button'
+import { Input } from './ui/input'
+import { Label } from './ui/label'
+import { useToast } from './ui/use-toast'
+import {
+  Card,
+  CardContent,
+  CardFooter,
+  CardHeader,
+  CardTitle
+} from '@/components/ui/card'
+
+const emptyTunnel = () => new main.Tunnel({ name: '', jumpHosts: '', forwards: [new main.Forward({ localPort: 0, remoteHost: '', remotePort: 0 })] })
+
+export function Tunnels () {
+  const columns: ColumnDef<main.TunnelStatus>[] = [
+    {
+      accessorKey: 'name',
+      header: () => <div className="text-center">Name</div>,
+      cell: ({row}) => row.original.tunnel.name,
+    },
+    {
+      accessorKey: 'jumpHosts',
+      header: () => <div className="text-center">Jump Hosts</div>,
+      cell: ({row}) => row.original.tunnel.jumpHosts,
+    },
+    {
+      accessorKey: 'forwards',
+      header: () => <div className="text-center">Forwards</div>,
+      cell: ({row}) => (
+        <div className='text-xs'>
+          {(row.original.tunnel.forwards ?? []).map((f, i) => <div key={i}>{f.localPort} → {f.remoteHost}:{f.remotePort}</div>)}
+        </div>
+      ),
+    },
+    {
+      accessorKey: 'state',
+      header: () => <div className="text-center">State</div>,
+      cell: ({row}) => <div className='text-xs break-all'>{row.original.state}{row.original.attempts > 0 ? ` (attempt ${row.original.attempts})` : ''} {row.original.error}</div>,
+    },
+    {
+      accessorKey: 'action',
+      header: () => <div className="text-center">Action</div>,
+      cell: ({row}) => {
+        const name = row.original.tunnel.name
+        const running = row.original.state !== 'down' || row.original.attempts > 0
+        return (
+          <div className='flex justify-center space-x-5'>
+            {running
+              ? <Button variant='outline' onClick={() => run(() => StopTunnel(name), `Tunnel ${name} has been stopped.`)}>Stop</Button>
+              : <Button onClick={() => run(() => StartTunnel(name), `Tunnel ${name} has been started.`)}>Start</Button>}
+            <Button variant='outline' onClick={() => setTunnel(new main.Tunnel(row.original.tunnel))}>Edit</Button>
+            <Button variant="destructive" onClick={() => run(() => DeleteTunnel(name), `Tunnel ${name} has been deleted.`)}>Delete</Button>
+          </div>
+        )
+      }
+    },
+  ]
+
+  const [, setLoading] = useRecoilState(loadingState)
+  const [, setStatuses] = useRecoilState(tunnelStatusState)
+  const [tunnels, setTunnels] = useState<main.TunnelStatus[]>([])
+  const [tunnel, setTunnel] = useState<main.Tunnel>(emptyTunnel())
+  const { toast } = useToast()
+
+  const refresh = () => GetTunnels().then(_tunnels => {
+    setTunnels(_tunnels)
+    setStatuses(Object.fromEntries(_tunnels.map(t => [t.tunnel.name, t])))
+  })
+
+  const run = async (action: () => Promise<void>, description: string) => {
+    try {
+      setLoading(true)
+      await action()
+      toast({
+        description,
+      })
+    } catch(error: any) {
+      toast({
+        description: error,
+      })
+    } finally {
+      await refresh().catch(() => {})
+      setLoading(false)
+    }
+  }
+
+  const save = () => run(async () => {
+    await SaveTunnel(tunnel)
+    setTunnel(emptyTunnel())
+  }, `Tunnel ${tunnel.name} has been saved, restart it to apply the changes.`)
+
+  const setForward = (i: number, forward: Partial<main.Forward>) => {
+    setTunnel(new main.Tunnel({ ...tunnel, forwards: tunnel.forwards.map((f, j) => j === i ? new main.Forward({ ...f, ...forward }) : f) }))
+  }
+
+  useEffect(() => {
+    refresh().catch((error: any) => {
+      toast({
+        description: error,
+      })
+    })
+    const interval = setInterval(() => refresh().catch(() => {}), 5000)
+    return () => clearInterval(interval)
+  }, [])
+
+  const editing = tunnels.some(t => t.tunnel.name === tunnel.name)
+
+  return (
+    <div className='w-full'>
+      <Card className='mb-10'>
+        <CardHeader>
+          <CardTitle className="text-center">SSH Tunnels</CardTitle>
+        </CardHeader>
+        <CardContent>
+          <DataTable columns={columns} data={tunnels} />
+        </CardContent>
+      </Card>
+      <Card>
+        <CardHeader>
+          <CardTitle className="text-center">{editing ? `Edit ${tunnel.name}` : 'New Tunnel'}</CardTitle>
+        </CardHeader>
+        <CardContent>
+          <form className="grid w-full items-end gap-x-10 gap-y-4 sm:grid-cols-2" onSubmit={e => {
+            e.preventDefault()
+            save()
+          }}>
+            <div className="flex flex-col space-y-1.5">
+              <Label htmlFor='tunnel-name'>Name</Label>
+              <Input id='tunnel-name' placeholder='bastion' value={tunnel.name} disabled={editing}
+                onChange={e => setTunnel(new main.Tunnel({ ...tunnel, name: e.target.value }))}/>
+            </div>
+            <div className="flex flex-col space-y-1.5">
+              <Label htmlFor='tunnel-jump-hosts'>Jump Hosts</Label>
+              <Input id='tunnel-jump-hosts' placeholder='ops@bastion:22,jump2' value={tunnel.jumpHosts}
+                onChange={e => setTunnel(new main.Tunnel({ ...tunnel, jumpHosts: e.target.value }))}/>
+            </div>
+            {tunnel.forwards.map((f, i) => (
+              <div className='grid grid-cols-4 gap-x-4 items-end sm:col-span-2' key={i}>
+                <div className="flex flex-col space-y-1.5">
+                  <Label htmlFor={`forward-local-${i}`}>Local Port</Label>
+                  <Input id={`forward-local-${i}`} type='number' min='1' placeholder='8080' value={f.localPort || ''}
+                    onChange={e => setForward(i, { localPort: Number(e.target.value) })}/>
+                </div>
+                <div className="flex flex-col space-y-1.5">
+                  <Label htmlFor={`forward-host-${i}`}>Remote Host</Label>
+                  <Input id={`forward-host-${i}`} placeholder='10.0.0.10' value={f.remoteHost}
+                    onChange={e => setForward(i, { remoteHost: e.target.value })}/>
+                </div>
+                <div className="flex flex-col space-y-1.5">
+                  <Label htmlFor={`forward-remote-${i}`}>Remote Port</Label>
+                  <Input id={`forward-remote-${i}`} type='number' min='1' placeholder='5000' value={f.remotePort || ''}
+                    onChange={e => setForward(i, { remotePort: Number(e.target.value) })}/>
+                </div>
+                <Button type='button' variant='destructive' disabled={tunnel.forwards.length === 1}
+                  onClick={() => setTunnel(new main.Tunnel({ ...tunnel, forwards: tunnel.forwards.filter((_, j) => j !== i) }))}>Remove</Button>
+              </div>
+            ))}
+            <button type='submit' hidden/>
+          </form>
+        </CardContent>
+        <CardFooter className="flex justify-between">
+          <Button type='button' variant='outline' onClick={() => setTunnel(emptyTunnel())}>Clear</Button>
+          <div className='flex space-x-4'>
+            <Button type='button' variant='outline'
+              onClick={() => setTunnel(new main.Tunnel({ ...tunnel, forwards: [...tunnel.forwards, new main.Forward({ localPort: 0, remoteHost: '', remotePort: 0 })] }))}>Add Forward</Button>
+            <Button type='button' disabled={!tunnel.name || !tunnel.jumpHosts} onClick={save}>Save</Button>
+          </div>
+        </CardFooter>
+      </Card>
+    </div>
+  )
+}
@@ -0,0 +1,115 @@
+import { Button } from '@/components/ui/button'
+import { Card, CardContent, CardHeader, CardTitle } from '@/components/ui/card'
+import { Input } from '@/components/ui/input'
+import { Label } from '@/components/ui/label'
+import { useEffect, useState } from 'react'
+import { useRecoilState } from 'recoil'
+import { loadingState } from '@/store/state'
+import { GetVaultStatus, LockVault, SetupVault, UnlockVault } from '../../wailsjs/go/main/App'
+import { main } from '../../wailsjs/go/models'
+import { useToast } from '@/components/ui/use-toast'
+
+type Props = {
+  onChange: () => void
+}
+
+const Vault = ({ onChange }: Props) => {
+
+  const [status, setStatus] = useState<main.VaultStatus>()
+  const [password, setPassword] = useState('')
+  const [confirmation, setConfirmation] = useState('')
+  const [, setLoading] = useRecoilState(loadingState)
+  const { toast } = useToast()
+
+  const refresh = () => GetVaultStatus().then(setStatus).catch((error: any) => {
+    toast({
+      description: error,
+    })
+  })
+
+  useEffect(() => {
+    refresh()
+  }, [])
+
+  const run = async (action: () => Promise<void>, description: string) => {
+    try {
+      setLoading(true)
+      await action()
+      setPassword('')
+      setConfirmation('')
+      await refresh()
+      onChange()
+      toast({
+        description,
+      })
+    } catch(error: any) {
+      toast({
+        description: error,
+      })
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  const setup = () => {
+    if (password !== confirmation) {
+      toast({
+        description: 'Master passwords do not match.',
+      })
+      return
+    }
+    run(() => SetupVault(password), 'Master password has been set, stored secrets are now encrypted.')
+  }
+
+  const unlock = () => run(() => UnlockVault(password), 'Secrets have been unlocked.')
+
+  const lock = () => run(LockVault, 'Secrets have been locked.')
+
+  if (!status) {
+    return null
+  }
+
+  return (
+    <Card className='mb-10'>
+      <CardHeader>
+        <CardTitle className="text-center">Secrets</CardTitle>
+      </CardHeader>
+      <CardContent>
+        {status.unlocked
+          ? (
+            <div className='flex items-center justify-between'>
+              <span>Secrets are unlocked.</span>
+              <Button type='button' variant='outline' onClick={lock}>Lock</Button>
+            </div>
+            )
+          : (
+            <form className="grid w-full items-end gap-x-10 gap-y-4 sm:grid-cols-2" onSubmit={e => {
+              e.preventDefault()
+              status.initialized ? unlock() : setup()
+            }}>
+              <p className='sm:col-span-2'>
+                {status.initialized
+                  ? 'Secrets are locked, unlock them to use tunnels, proxies and PIN keys or to save secret settings.'
+                  : 'Set a master password to encrypt the stored secrets, they cannot be used until then.'}
+              </p>
+              <div className="flex flex-col space-y-1.5">
+                <Label htmlFor='master-password'>Master Password</Label>
+                <Input id='master-password' type='password' value={password} onChange={e => setPassword(e.target.value)}/>
+              </div>
+              {!status.initialized && (
+                <div className="flex flex-col space-y-1.5">
+                  <Label htmlFor='master-password-confirmation'>Confirm Master Password</Label>
+                  <Input id='master-password-confirmation' type='password' value={confirmation} onChange={e => setConfirmation(e.target.value)}/>
+                </div>
+              )}
+              <div className='flex space-x-4'>
+                <Button type='submit' disabled={!password}>{status.initialized ? 'Unlock' : 'Set Master Password'}</Button>
+              </div>
+            </form>
+            )}
+      </CardContent>
+    </Card>
+  )
+}
+
+export default Vault
@@ -56,7 +56,16 @@ export enum Transaction {
     IBFTD = 'IBFTD',
     ELOAD = 'ELOAD',
     BILLS = 'BILLS',
-    PURCHASE = 'PURCHASE'
+    PURCHASE = 'PURCHASE',
+    PRE_AUTH = 'PRE_AUTH',
+    INCREMENTAL_AUTH = 'INCREMENTAL_AUTH',
+    PRE_AUTH_COMPLETION = 'PRE_AUTH_COMPLETION',
+    PRE_AUTH_CANCEL = 'PRE_AUTH_CANCEL',
+    DEPOSIT = 'DEPOSIT',
+    CHEQUE_DEPOSIT = 'CHEQUE_DEPOSIT',
+    MINI_STATEMENT = 'MINI_STATEMENT',
+    PIN_CHANGE = 'PIN_CHANGE',
+    CARDLESS_WITHDRAW = 'CARDLESS_WITHDRAW'
 }
 
 export enum Channel {
@@ -81,3 +90,51 @@ export enum MTI {
 
 }
 
+
+export enum Fault {
+    CORRUPT_LENGTH = 'CORRUPT_LENGTH',
+    TRUNCATE_FRAME = 'TRUNCATE_FRAME',
+    FLIP_BITMAP = 'FLIP_BITMAP',
+    OVERLENGTH_LLVAR = 'OVERLENGTH_LLVAR',
+    WRONG_MTI = 'WRONG_MTI',
+    DUPLICATE_STAN = 'DUPLICATE_STAN',
+    DROP_CORTEX_HEADER = 'DROP_CORTEX_HEADER'
+}
+
+export type IsoMessage = {
+    mti: string
+    fields: Record<string, string>
+}
+
+export type ServerMessage = {
+    id: number
+    time: string
+    remote: string
+    inbound: boolean
+    message: IsoMessage
+    rule?: number
+    error?: string
+}
+
+export type LinkMessage = {
+    id: number
+    time: string
+    switch: string
+    inbound: boolean
+    autoReply: boolean
+    message: IsoMessage
+    error?: string
+}
+
+export type HostKeyPrompt = {
+    host: string
+    keyType: string
+    fingerprint: string
+}
+
+export type SshChallenge = {
+    user: string
+    instruction: string
+    questions: string[]
+    echos: boolean[]
+}
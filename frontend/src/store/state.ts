@@ -24,4 +24,9 @@ export const keyState = atom({
 export const tunnelState = atom({
   key: 'tunnel',
   default: false
+})
+
+export const tunnelStatusState = atom<Record<string, main.TunnelStatus>>({
+  key: 'tunnelStatus',
+  default: {}
 })
\ No newline at end of file
@@ -2,20 +2,118 @@
 // This file is automatically generated. DO NOT EDIT
 import {main} from '../models';
 
+export function AnswerHostKey(arg1:boolean):Promise<void>;
+
+export function AnswerServerRequest(arg1:number,arg2:string):Promise<void>;
+
+export function AnswerSshChallenge(arg1:Array<string>):Promise<void>;
+
+export function CloseLinks():Promise<void>;
+
 export function CloseTunnel():Promise<void>;
 
+export function CreateProfile(arg1:string,arg2:string):Promise<void>;
+
+export function DeleteProfile(arg1:string):Promise<void>;
+
+export function DeleteTunnel(arg1:string):Promise<void>;
+
+export function ExportProfiles():Promise<string>;
+
+export function ExportReconReport(arg1:main.ReconReport):Promise<string>;
+
+export function GetActiveProfile():Promise<string>;
+
 export function GetConfigs():Promise<Array<main.Config>>;
 
+export function GetCurrencies():Promise<Array<main.CurrencyInfo>>;
+
+export function GetFaultCases():Promise<Array<main.FaultCase>>;
+
+export function GetKnownHosts():Promise<Array<main.KnownHost>>;
+
+export function GetLinks():Promise<Array<main.LinkStatus>>;
+
+export function GetMessageLifecycle(arg1:number):Promise<main.MessageLifecycle>;
+
 export function GetMessages(arg1:number):Promise<Array<main.Message>>;
 
+export function GetPreAuth(arg1:number):Promise<main.PreAuth>;
+
+export function GetProfiles():Promise<Array<main.Profile>>;
+
+export function GetReconMapping(arg1:string):Promise<main.ReconMapping>;
+
+export function GetReconciliationTotals(arg1:main.ReconciliationRequest):Promise<main.ReconciliationTotals>;
+
+export function GetSafItems():Promise<Array<main.SafItem>>;
+
+export function GetServerRules():Promise<Array<main.ServerRule>>;
+
+export function GetServerStatus():Promise<main.ServerStatus>;
+
+export function GetTunnels():Promise<Array<main.TunnelStatus>>;
+
+export function GetVaultStatus():Promise<main.VaultStatus>;
+
+export function ImportProfiles():Promise<number>;
+
+export function ImportSettlement(arg1:string,arg2:string,arg3:string):Promise<main.ReconReport>;
+
+export function ImportSshKey():Promise<void>;
+
+export function LockVault():Promise<void>;
+
 export function OpenFileDialog():Promise<string>;
 
 export function PingTunnel():Promise<void>;
 
+export function PurgeSaf(arg1:number):Promise<void>;
+
+export function RemoveKnownHost(arg1:string,arg2:string):Promise<void>;
+
+export function ResendSafItem(arg1:number):Promise<void>;
+
+export function SaveReconMapping(arg1:main.ReconMapping):Promise<void>;
+
+export function SaveServerRules(arg1:Array<main.ServerRule>):Promise<void>;
+
+export function SaveTunnel(arg1:main.Tunnel):Promise<void>;
+
+export function SendAdviceMessage(arg1:main.Message):Promise<main.SafItem>;
+
+export function SendDepositCompletion(arg1:number,arg2:main.DepositCompletion):Promise<main.SafItem>;
+
+export function SendFaultMessage(arg1:main.Message,arg2:string):Promise<main.AtmResponse>;
+
 export function SendFinancialMessage(arg1:main.Message):Promise<main.AtmResponse>;
 
-export function SendReversalMessage(arg1:number):Promise<main.AtmResponse>;
+export function SendPreAuthFollowUp(arg1:number,arg2:main.PreAuthFollowUp):Promise<main.AtmResponse>;
+
+export function SendReconciliation(arg1:main.ReconciliationRequest):Promise<main.ReconciliationResult>;
+
+export function SendReversalAdvice(arg1:number,arg2:main.ReversalOptions):Promise<main.SafItem>;
+
+export function SendReversalMessage(arg1:number,arg2:main.ReversalOptions):Promise<main.AtmResponse>;
+
+export function SetupVault(arg1:string):Promise<void>;
+
+export function StartServer(arg1:string,arg2:number,arg3:boolean):Promise<void>;
+
+export function StartTunnel(arg1:string):Promise<void>;
+
+export function StopServer():Promise<void>;
+
+export function StopTunnel(arg1:string):Promise<void>;
+
+export function SwitchProfile(arg1:string):Promise<void>;
+
+export function TestConnection(arg1:string):Promise<main.ConnectionTest>;
+
+export function UnlockVault(arg1:string):Promise<void>;
 
 export function UpdateConfigs(arg1:Array<main.Config>):Promise<void>;
 
 export function UseTunnel():Promise<void>;
+
+export function ValidateMessage(arg1:main.Message):Promise<Array<main.FieldError>>;
@@ -2,18 +2,130 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AnswerHostKey(arg1) {
+  return window['go']['main']['App']['AnswerHostKey'](arg1);
+}
+
+export function AnswerServerRequest(arg1, arg2) {
+  return window['go']['main']['App']['AnswerServerRequest'](arg1, arg2);
+}
+
+export function AnswerSshChallenge(arg1) {
+  return window['go']['main']['App']['AnswerSshChallenge'](arg1);
+}
+
+export function CloseLinks() {
+  return window['go']['main']['App']['CloseLinks']();
+}
+
 export function CloseTunnel() {
   return window['go']['main']['App']['CloseTunnel']();
 }
 
+export function CreateProfile(arg1, arg2) {
+  return window['go']['main']['App']['CreateProfile'](arg1, arg2);
+}
+
+export function DeleteProfile(arg1) {
+  return window['go']['main']['App']['DeleteProfile'](arg1);
+}
+
+export function DeleteTunnel(arg1) {
+  return window['go']['main']['App']['DeleteTunnel'](arg1);
+}
+
+export function ExportProfiles() {
+  return window['go']['main']['App']['ExportProfiles']();
+}
+
+export function ExportReconReport(arg1) {
+  return window['go']['main']['App']['ExportReconReport'](arg1);
+}
+
+export function GetActiveProfile() {
+  return window['go']['main']['App']['GetActiveProfile']();
+}
+
 export function GetConfigs() {
   return window['go']['main']['App']['GetConfigs']();
 }
 
+export function GetCurrencies() {
+  return window['go']['main']['App']['GetCurrencies']();
+}
+
+export function GetFaultCases() {
+  return window['go']['main']['App']['GetFaultCases']();
+}
+
+export function GetKnownHosts() {
+  return window['go']['main']['App']['GetKnownHosts']();
+}
+
+export function GetLinks() {
+  return window['go']['main']['App']['GetLinks']();
+}
+
+export function GetMessageLifecycle(arg1) {
+  return window['go']['main']['App']['GetMessageLifecycle'](arg1);
+}
+
 export function GetMessages(arg1) {
   return window['go']['main']['App']['GetMessages'](arg1);
 }
 
+export function GetPreAuth(arg1) {
+  return window['go']['main']['App']['GetPreAuth'](arg1);
+}
+
+export function GetProfiles() {
+  return window['go']['main']['App']['GetProfiles']();
+}
+
+export function GetReconMapping(arg1) {
+  return window['go']['main']['App']['GetReconMapping'](arg1);
+}
+
+export function GetReconciliationTotals(arg1) {
+  return window['go']['main']['App']['GetReconciliationTotals'](arg1);
+}
+
+export function GetSafItems() {
+  return window['go']['main']['App']['GetSafItems']();
+}
+
+export function GetServerRules() {
+  return window['go']['main']['App']['GetServerRules']();
+}
+
+export function GetServerStatus() {
+  return window['go']['main']['App']['GetServerStatus']();
+}
+
+export function GetTunnels() {
+  return window['go']['main']['App']['GetTunnels']();
+}
+
+export function GetVaultStatus() {
+  return window['go']['main']['App']['GetVaultStatus']();
+}
+
+export function ImportProfiles() {
+  return window['go']['main']['App']['ImportProfiles']();
+}
+
+export function ImportSettlement(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ImportSettlement'](arg1, arg2, arg3);
+}
+
+export function ImportSshKey() {
+  return window['go']['main']['App']['ImportSshKey']();
+}
+
+export function LockVault() {
+  return window['go']['main']['App']['LockVault']();
+}
+
 export function OpenFileDialog() {
   return window['go']['main']['App']['OpenFileDialog']();
 }
@@ -22,12 +134,92 @@ export function PingTunnel() {
   return window['go']['main']['App']['PingTunnel']();
 }
 
+export function PurgeSaf(arg1) {
+  return window['go']['main']['App']['PurgeSaf'](arg1);
+}
+
+export function RemoveKnownHost(arg1, arg2) {
+  return window['go']['main']['App']['RemoveKnownHost'](arg1, arg2);
+}
+
+export function ResendSafItem(arg1) {
+  return window['go']['main']['App']['ResendSafItem'](arg1);
+}
+
+export function SaveReconMapping(arg1) {
+  return window['go']['main']['App']['SaveReconMapping'](arg1);
+}
+
+export function SaveServerRules(arg1) {
+  return window['go']['main']['App']['SaveServerRules'](arg1);
+}
+
+export function SaveTunnel(arg1) {
+  return window['go']['main']['App']['SaveTunnel'](arg1);
+}
+
+export function SendAdviceMessage(arg1) {
+  return window['go']['main']['App']['SendAdviceMessage'](arg1);
+}
+
+export function SendDepositCompletion(arg1, arg2) {
+  return window['go']['main']['App']['SendDepositCompletion'](arg1, arg2);
+}
+
+export function SendFaultMessage(arg1, arg2) {
+  return window['go']['main']['App']['SendFaultMessage'](arg1, arg2);
+}
+
 export function SendFinancialMessage(arg1) {
   return window['go']['main']['App']['SendFinancialMessage'](arg1);
 }
 
-export function SendReversalMessage(arg1) {
-  return window['go']['main']['App']['SendReversalMessage'](arg1);
+export function SendPreAuthFollowUp(arg1, arg2) {
+  return window['go']['main']['App']['SendPreAuthFollowUp'](arg1, arg2);
+}
+
+export function SendReconciliation(arg1) {
+  return window['go']['main']['App']['SendReconciliation'](arg1);
+}
+
+export function SendReversalAdvice(arg1, arg2) {
+  return window['go']['main']['App']['SendReversalAdvice'](arg1, arg2);
+}
+
+export function SendReversalMessage(arg1, arg2) {
+  return window['go']['main']['App']['SendReversalMessage'](arg1, arg2);
+}
+
+export function SetupVault(arg1) {
+  return window['go']['main']['App']['SetupVault'](arg1);
+}
+
+export function StartServer(arg1, arg2, arg3) {
+  return window['go']['main']['App']['StartServer'](arg1, arg2, arg3);
+}
+
+export function StartTunnel(arg1) {
+  return window['go']['main']['App']['StartTunnel'](arg1);
+}
+
+export function StopServer() {
+  return window['go']['main']['App']['StopServer']();
+}
+
+export function StopTunnel(arg1) {
+  return window['go']['main']['App']['StopTunnel'](arg1);
+}
+
+export function SwitchProfile(arg1) {
+  return window['go']['main']['App']['SwitchProfile'](arg1);
+}
+
+export function TestConnection(arg1) {
+  return window['go']['main']['App']['TestConnection'](arg1);
+}
+
+export function UnlockVault(arg1) {
+  return window['go']['main']['App']['UnlockVault'](arg1);
 }
 
 export function UpdateConfigs(arg1) {
@@ -37,3 +229,7 @@ export function UpdateConfigs(arg1) {
 export function UseTunnel() {
   return window['go']['main']['App']['UseTunnel']();
 }
+
+export function ValidateMessage(arg1) {
+  return window['go']['main']['App']['ValidateMessage'](arg1);
+}
@@ -5,6 +5,9 @@ export namespace main {
 	    responseCode: string;
 	    balance: string;
 	    rrn: string;
+	    statement?: StatementEntry[];
+	    referenceCode?: string;
+	    currency?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new AtmResponse(source);
@@ -16,6 +19,49 @@ export namespace main {
 	        this.responseCode = source["responseCode"];
 	        this.balance = source["balance"];
 	        this.rrn = source["rrn"];
+	        this.statement = this.convertValues(source["statement"], StatementEntry);
+	        this.referenceCode = source["referenceCode"];
+	        this.currency = source["currency"];
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CertificateInfo {
+	    subject: string;
+	    issuer: string;
+	    notBefore: string;
+	    notAfter: string;
+	    sha256: string;
+	    pin: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CertificateInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.subject = source["subject"];
+	        this.issuer = source["issuer"];
+	        this.notBefore = source["notBefore"];
+	        this.notAfter = source["notAfter"];
+	        this.sha256 = source["sha256"];
+	        this.pin = source["pin"];
 	    }
 	}
 	export class Config {
@@ -32,6 +78,168 @@ export namespace main {
 	        this.value = source["value"];
 	    }
 	}
+	export class ConnectionTest {
+	    address: string;
+	    tls: boolean;
+	    version?: string;
+	    cipherSuite?: string;
+	    serverName?: string;
+	    certificates?: CertificateInfo[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ConnectionTest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.address = source["address"];
+	        this.tls = source["tls"];
+	        this.version = source["version"];
+	        this.cipherSuite = source["cipherSuite"];
+	        this.serverName = source["serverName"];
+	        this.certificates = this.convertValues(source["certificates"], CertificateInfo);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CurrencyInfo {
+	    code: string;
+	    alpha: string;
+	    exponent: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CurrencyInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.code = source["code"];
+	        this.alpha = source["alpha"];
+	        this.exponent = source["exponent"];
+	    }
+	}
+	export class DepositCompletion {
+	    countedAmount: number;
+	    items: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DepositCompletion(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.countedAmount = source["countedAmount"];
+	        this.items = source["items"];
+	    }
+	}
+	export class FaultCase {
+	    fault: string;
+	    description: string;
+	    switches: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FaultCase(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.fault = source["fault"];
+	        this.description = source["description"];
+	        this.switches = source["switches"];
+	    }
+	}
+	export class FieldError {
+	    field: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FieldError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.message = source["message"];
+	    }
+	}
+	export class Forward {
+	    localPort: number;
+	    remoteHost: string;
+	    remotePort: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Forward(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.localPort = source["localPort"];
+	        this.remoteHost = source["remoteHost"];
+	        this.remotePort = source["remotePort"];
+	    }
+	}
+	export class IsoMessage {
+	    mti: string;
+	    fields: {[key: string]: string};
+	
+	    static createFrom(source: any = {}) {
+	        return new IsoMessage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mti = source["mti"];
+	        this.fields = source["fields"];
+	    }
+	}
+	export class KnownHost {
+	    hosts: string;
+	    keyType: string;
+	    fingerprint: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new KnownHost(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hosts = source["hosts"];
+	        this.keyType = source["keyType"];
+	        this.fingerprint = source["fingerprint"];
+	    }
+	}
+	export class LinkStatus {
+	    switch: string;
+	    remote: string;
+	    pending: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new LinkStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.switch = source["switch"];
+	        this.remote = source["remote"];
+	        this.pending = source["pending"];
+	    }
+	}
 	export class Message {
 	    transaction: string;
 	    switch: string;
@@ -42,6 +250,9 @@ export namespace main {
 	    transactionFee?: number;
 	    terminalNameAndLocation: string;
 	    currencyCode: string;
+	    billingAmount?: number;
+	    billingCurrencyCode?: string;
+	    conversionRate?: number;
 	    terminalId: string;
 	    sourceAccount?: string;
 	    destinationAccount?: string;
@@ -56,6 +267,20 @@ export namespace main {
 	    originalDataElements?: string;
 	    mti?: string;
 	    processCod?: string;
+	    replacementAmount?: number;
+	    reversalReason?: string;
+	    parentId?: number;
+	    responseCode?: string;
+	    reversalStatus?: string;
+	    reversalResponseCode?: string;
+	    authorizationId?: number;
+	    depositItems?: number;
+	    chequeNumber?: string;
+	    pin?: string;
+	    newPin?: string;
+	    referenceCode?: string;
+	    mobileNumber?: string;
+	    otp?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new Message(source);
@@ -72,6 +297,9 @@ export namespace main {
 	        this.transactionFee = source["transactionFee"];
 	        this.terminalNameAndLocation = source["terminalNameAndLocation"];
 	        this.currencyCode = source["currencyCode"];
+	        this.billingAmount = source["billingAmount"];
+	        this.billingCurrencyCode = source["billingCurrencyCode"];
+	        this.conversionRate = source["conversionRate"];
 	        this.terminalId = source["terminalId"];
 	        this.sourceAccount = source["sourceAccount"];
 	        this.destinationAccount = source["destinationAccount"];
@@ -86,6 +314,558 @@ export namespace main {
 	        this.originalDataElements = source["originalDataElements"];
 	        this.mti = source["mti"];
 	        this.processCod = source["processCod"];
+	        this.replacementAmount = source["replacementAmount"];
+	        this.reversalReason = source["reversalReason"];
+	        this.parentId = source["parentId"];
+	        this.responseCode = source["responseCode"];
+	        this.reversalStatus = source["reversalStatus"];
+	        this.reversalResponseCode = source["reversalResponseCode"];
+	        this.authorizationId = source["authorizationId"];
+	        this.depositItems = source["depositItems"];
+	        this.chequeNumber = source["chequeNumber"];
+	        this.pin = source["pin"];
+	        this.newPin = source["newPin"];
+	        this.referenceCode = source["referenceCode"];
+	        this.mobileNumber = source["mobileNumber"];
+	        this.otp = source["otp"];
+	    }
+	}
+	export class MessageLifecycle {
+	    original: Message;
+	    reversals: Message[];
+	    status: string;
+	    responseCode: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new MessageLifecycle(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.original = this.convertValues(source["original"], Message);
+	        this.reversals = this.convertValues(source["reversals"], Message);
+	        this.status = source["status"];
+	        this.responseCode = source["responseCode"];
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PreAuth {
+	    preAuth: Message;
+	    followUps: Message[];
+	    authorized: number;
+	    closed: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new PreAuth(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.preAuth = this.convertValues(source["preAuth"], Message);
+	        this.followUps = this.convertValues(source["followUps"], Message);
+	        this.authorized = source["authorized"];
+	        this.closed = source["closed"];
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PreAuthFollowUp {
+	    transaction: string;
+	    amount: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PreAuthFollowUp(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.transaction = source["transaction"];
+	        this.amount = source["amount"];
+	    }
+	}
+	export class Profile {
+	    name: string;
+	    configs: Config[];
+	    tunnels?: Tunnel[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Profile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.configs = this.convertValues(source["configs"], Config);
+	        this.tunnels = this.convertValues(source["tunnels"], Tunnel);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ReconEntry {
+	    status: string;
+	    line?: number;
+	    messageId?: number;
+	    rrn: string;
+	    stan: string;
+	    terminalId: string;
+	    fileAmount: number;
+	    messageAmount: number;
+	    currency?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReconEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.status = source["status"];
+	        this.line = source["line"];
+	        this.messageId = source["messageId"];
+	        this.rrn = source["rrn"];
+	        this.stan = source["stan"];
+	        this.terminalId = source["terminalId"];
+	        this.fileAmount = source["fileAmount"];
+	        this.messageAmount = source["messageAmount"];
+	        this.currency = source["currency"];
+	    }
+	}
+	export class ReconMapping {
+	    switch: string;
+	    format: string;
+	    delimiter: string;
+	    headerLines: number;
+	    footerLines: number;
+	    rrn: string;
+	    stan: string;
+	    terminalId: string;
+	    amount: string;
+	    minorUnits: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReconMapping(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.switch = source["switch"];
+	        this.format = source["format"];
+	        this.delimiter = source["delimiter"];
+	        this.headerLines = source["headerLines"];
+	        this.footerLines = source["footerLines"];
+	        this.rrn = source["rrn"];
+	        this.stan = source["stan"];
+	        this.terminalId = source["terminalId"];
+	        this.amount = source["amount"];
+	        this.minorUnits = source["minorUnits"];
+	    }
+	}
+	export class ReconReport {
+	    switch: string;
+	    file: string;
+	    from: string;
+	    to: string;
+	    matched: number;
+	    missing: number;
+	    unexpected: number;
+	    mismatched: number;
+	    entries: ReconEntry[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ReconReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.switch = source["switch"];
+	        this.file = source["file"];
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.matched = source["matched"];
+	        this.missing = source["missing"];
+	        this.unexpected = source["unexpected"];
+	        this.mismatched = source["mismatched"];
+	        this.entries = this.convertValues(source["entries"], ReconEntry);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ReconciliationRequest {
+	    switch: string;
+	    from: string;
+	    to: string;
+	    currencyCode: string;
+	    acquiringInstitutionCode: string;
+	    advice: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReconciliationRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.switch = source["switch"];
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.currencyCode = source["currencyCode"];
+	        this.acquiringInstitutionCode = source["acquiringInstitutionCode"];
+	        this.advice = source["advice"];
+	    }
+	}
+	export class ReconciliationResult {
+	    request: IsoMessage;
+	    response: IsoMessage;
+	    local: ReconciliationTotals;
+	    host: ReconciliationTotals;
+	    hostTotals: boolean;
+	    responseCode: string;
+	    settlementCode: string;
+	    differences: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ReconciliationResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.request = this.convertValues(source["request"], IsoMessage);
+	        this.response = this.convertValues(source["response"], IsoMessage);
+	        this.local = this.convertValues(source["local"], ReconciliationTotals);
+	        this.host = this.convertValues(source["host"], ReconciliationTotals);
+	        this.hostTotals = source["hostTotals"];
+	        this.responseCode = source["responseCode"];
+	        this.settlementCode = source["settlementCode"];
+	        this.differences = source["differences"];
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ReconciliationTotals {
+	    creditsNumber: number;
+	    creditsReversalNumber: number;
+	    debitsNumber: number;
+	    debitsReversalNumber: number;
+	    creditsAmount: number;
+	    creditsReversalAmount: number;
+	    debitsAmount: number;
+	    debitsReversalAmount: number;
+	    netAmount: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReconciliationTotals(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.creditsNumber = source["creditsNumber"];
+	        this.creditsReversalNumber = source["creditsReversalNumber"];
+	        this.debitsNumber = source["debitsNumber"];
+	        this.debitsReversalNumber = source["debitsReversalNumber"];
+	        this.creditsAmount = source["creditsAmount"];
+	        this.creditsReversalAmount = source["creditsReversalAmount"];
+	        this.debitsAmount = source["debitsAmount"];
+	        this.debitsReversalAmount = source["debitsReversalAmount"];
+	        this.netAmount = source["netAmount"];
+	    }
+	}
+	export class ReversalOptions {
+	    dispensedAmount: number;
+	    reason: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReversalOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dispensedAmount = source["dispensedAmount"];
+	        this.reason = source["reason"];
+	    }
+	}
+	export class SafItem {
+	    id: number;
+	    messageId: number;
+	    switch: string;
+	    mti: string;
+	    status: string;
+	    attempts: number;
+	    lastError: string;
+	    createdAt: string;
+	    lastAttemptAt: string;
+	    transaction: string;
+	    traceNumber: string;
+	    amount: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SafItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.messageId = source["messageId"];
+	        this.switch = source["switch"];
+	        this.mti = source["mti"];
+	        this.status = source["status"];
+	        this.attempts = source["attempts"];
+	        this.lastError = source["lastError"];
+	        this.createdAt = source["createdAt"];
+	        this.lastAttemptAt = source["lastAttemptAt"];
+	        this.transaction = source["transaction"];
+	        this.traceNumber = source["traceNumber"];
+	        this.amount = source["amount"];
+	    }
+	}
+	export class ServerRule {
+	    id?: number;
+	    mti: string;
+	    processCode: string;
+	    panPrefix: string;
+	    responseCode: string;
+	    balance: string;
+	    delay: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ServerRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.mti = source["mti"];
+	        this.processCode = source["processCode"];
+	        this.panPrefix = source["panPrefix"];
+	        this.responseCode = source["responseCode"];
+	        this.balance = source["balance"];
+	        this.delay = source["delay"];
+	    }
+	}
+	export class ServerStatus {
+	    running: boolean;
+	    switch?: string;
+	    port?: number;
+	    interactive: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ServerStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.running = source["running"];
+	        this.switch = source["switch"];
+	        this.port = source["port"];
+	        this.interactive = source["interactive"];
+	    }
+	}
+	export class StatementEntry {
+	    date: string;
+	    description: string;
+	    amount: number;
+	    credit: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new StatementEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = source["date"];
+	        this.description = source["description"];
+	        this.amount = source["amount"];
+	        this.credit = source["credit"];
+	    }
+	}
+	export class Tunnel {
+	    id?: number;
+	    name: string;
+	    jumpHosts: string;
+	    forwards: Forward[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Tunnel(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.jumpHosts = source["jumpHosts"];
+	        this.forwards = this.convertValues(source["forwards"], Forward);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TunnelMetrics {
+	    active: number;
+	    total: number;
+	    bytesIn: number;
+	    bytesOut: number;
+	    dialFailures: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TunnelMetrics(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.active = source["active"];
+	        this.total = source["total"];
+	        this.bytesIn = source["bytesIn"];
+	        this.bytesOut = source["bytesOut"];
+	        this.dialFailures = source["dialFailures"];
+	    }
+	}
+	export class TunnelStatus {
+	    tunnel: Tunnel;
+	    state: string;
+	    up: boolean;
+	    attempts: number;
+	    missed: number;
+	    metrics: TunnelMetrics;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TunnelStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tunnel = this.convertValues(source["tunnel"], Tunnel);
+	        this.state = source["state"];
+	        this.up = source["up"];
+	        this.attempts = source["attempts"];
+	        this.missed = source["missed"];
+	        this.metrics = this.convertValues(source["metrics"], TunnelMetrics);
+	        this.error = source["error"];
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class VaultStatus {
+	    initialized: boolean;
+	    unlocked: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new VaultStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.initialized = source["initialized"];
+	        this.unlocked = source["unlocked"];
 	    }
 	}
 
@@ -3,10 +3,24 @@ package main
 import (
 	"fmt"
 	"math/rand"
+	"strconv"
 )
 
-func moveDecimalRight(amount float64) string {
-	return fmt.Sprint(int(amount * 100))
+func moveDecimalRight(amount float64, currency Currency) string {
+	return strconv.FormatInt(minorUnits(amount, currency), 10)
+}
+
+// serializeAdditionalData is field 48 of a request.
+func serializeAdditionalData(message Message) string {
+	switch {
+	case isDeposit(message.Transaction):
+		return serializeDepositData(message)
+	case message.Transaction == PIN_CHANGE:
+		return message.NewPinBlock
+	case message.Transaction == CARDLESS_WITHDRAW:
+		return serializeCardlessData(message)
+	}
+	return ""
 }
 
 func padLeftWithZeros(input string, length int) string {
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const hostKeyPromptTimeout = 2 * time.Minute
+
+type hostKeyService struct {
+	ctx        context.Context
+	path       string
+	systemPath string
+	mu         sync.Mutex
+	answer     chan bool
+}
+
+type KnownHost struct {
+	Hosts       string `json:"hosts"`
+	KeyType     string `json:"keyType"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+type HostKeyPrompt struct {
+	Host        string `json:"host"`
+	KeyType     string `json:"keyType"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+func newHostKeyService(ctx context.Context, dirname string) *hostKeyService {
+	return &hostKeyService{
+		ctx:        ctx,
+		path:       filepath.Join(dirname, "atm_known_hosts"),
+		systemPath: filepath.Join(dirname, ".ssh", "known_hosts"),
+	}
+}
+
+// callback checks host keys against ~/.ssh/known_hosts and the app store,
+// where accepted unknown hosts are pinned.
+func (s *hostKeyService) callback() (ssh.HostKeyCallback, error) {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_RDONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+	files := []string{s.path}
+	if _, err := os.Stat(s.systemPath); err == nil {
+		files = append(files, s.systemPath)
+	}
+	known, err := knownhosts.New(files...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known hosts: %w", err)
+	}
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := known(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if !errors.As(err, &keyErr) {
+			return err
+		}
+		if len(keyErr.Want) > 0 {
+			return fmt.Errorf("host key mismatch for %s: got %s %s, known %s", hostname, key.Type(), ssh.FingerprintSHA256(key), keyErr.Want[0].String())
+		}
+		accepted, err := s.prompt(hostname, key)
+		if err != nil {
+			return err
+		}
+		if !accepted {
+			return fmt.Errorf("host key for %s was rejected", hostname)
+		}
+		return s.pin(hostname, key)
+	}, nil
+}
+
+func (s *hostKeyService) prompt(hostname string, key ssh.PublicKey) (bool, error) {
+	s.mu.Lock()
+	if s.answer != nil {
+		s.mu.Unlock()
+		return false, errors.New("another host key prompt is pending")
+	}
+	answer := make(chan bool, 1)
+	s.answer = answer
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		if s.answer == answer {
+			s.answer = nil
+		}
+		s.mu.Unlock()
+	}()
+
+	runtime.EventsEmit(s.ctx, "hostkey", HostKeyPrompt{
+		Host:        hostname,
+		KeyType:     key.Type(),
+		Fingerprint: ssh.FingerprintSHA256(key),
+	})
+	select {
+	case accepted := <-answer:
+		return accepted, nil
+	case <-time.After(hostKeyPromptTimeout):
+		return false, fmt.Errorf("no answer for the host key of %s", hostname)
+	}
+}
+
+func (s *hostKeyService) respond(accepted bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.answer == nil {
+		return errors.New("no host key prompt is pending")
+	}
+	s.answer <- accepted
+	s.answer = nil
+	return nil
+}
+
+func (s *hostKeyService) pin(hostname string, key ssh.PublicKey) error {
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintln(f, knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key))
+	if err == nil {
+		log.Printf("pinned host key %s for %s", ssh.FingerprintSHA256(key), hostname)
+	}
+	return err
+}
+
+func (s *hostKeyService) list() ([]KnownHost, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []KnownHost{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	knownHosts := []KnownHost{}
+	for len(data) > 0 {
+		var hosts []string
+		var key ssh.PublicKey
+		_, hosts, key, _, data, err = ssh.ParseKnownHosts(data)
+		if err != nil {
+			break
+		}
+		knownHosts = append(knownHosts, KnownHost{
+			Hosts:       strings.Join(hosts, ","),
+			KeyType:     key.Type(),
+			Fingerprint: ssh.FingerprintSHA256(key),
+		})
+	}
+	return knownHosts, nil
+}
+
+func (s *hostKeyService) remove(hosts string, fingerprint string) error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+	var kept bytes.Buffer
+	removed := false
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		_, h, key, _, _, err := ssh.ParseKnownHosts(line)
+		if err == nil && strings.Join(h, ",") == hosts && ssh.FingerprintSHA256(key) == fingerprint {
+			removed = true
+			continue
+		}
+		if len(bytes.TrimSpace(line)) > 0 {
+			kept.Write(line)
+			kept.WriteByte('\n')
+		}
+	}
+	if !removed {
+		return fmt.Errorf("no pinned key %s for %s", fingerprint, hosts)
+	}
+	return os.WriteFile(s.path, kept.Bytes(), 0600)
+}
+
+func (a *App) AnswerHostKey(accepted bool) error {
+	err := a.hostKeyService.respond(accepted)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	return nil
+}
+
+func (a *App) GetKnownHosts() ([]KnownHost, error) {
+	knownHosts, err := a.hostKeyService.list()
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return nil, err
+	}
+	return knownHosts, nil
+}
+
+func (a *App) RemoveKnownHost(hosts string, fingerprint string) error {
+	err := a.hostKeyService.remove(hosts, fingerprint)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	return nil
+}
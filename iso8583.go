@@ -17,8 +17,10 @@ type Fields struct {
 	Field003    string   `xml:"Field_003"`
 	Field004    string   `xml:"Field_004"`
 	Field005    string   `xml:"Field_005"`
+	Field006    string   `xml:"Field_006"`
 	Field007    string   `xml:"Field_007"`
 	Field009    string   `xml:"Field_009"`
+	Field010    string   `xml:"Field_010"`
 	Field011    string   `xml:"Field_011"`
 	Field012    string   `xml:"Field_012"`
 	Field013    string   `xml:"Field_013"`
@@ -50,6 +52,7 @@ type Fields struct {
 	Field048    string   `xml:"Field_048"`
 	Field049    string   `xml:"Field_049"`
 	Field050    string   `xml:"Field_050"`
+	Field051    string   `xml:"Field_051"`
 	Field052    string   `xml:"Field_052"`
 	Field053    string   `xml:"Field_053"`
 	Field054    string   `xml:"Field_054"`
@@ -138,12 +141,18 @@ type Field004 string
 // Field005 ...
 type Field005 string
 
+// Field006 ...
+type Field006 string
+
 // Field007 ...
 type Field007 string
 
 // Field009 ...
 type Field009 string
 
+// Field010 ...
+type Field010 string
+
 // Field011 ...
 type Field011 string
 
@@ -237,6 +246,9 @@ type Field049 string
 // Field050 ...
 type Field050 string
 
+// Field051 ...
+type Field051 string
+
 // Field052 ...
 type Field052 string
 
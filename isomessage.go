@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/moov-io/iso8583"
+)
+
+// IsoMessage holds the fields of any switch by number, "127.25" for
+// Postilion subfields.
+type IsoMessage struct {
+	Mti    string            `json:"mti"`
+	Fields map[string]string `json:"fields"`
+}
+
+func (m IsoMessage) field(n int) string {
+	return m.Fields[strconv.Itoa(n)]
+}
+
+// fieldKeys returns the keys of the message in field order.
+func (m IsoMessage) fieldKeys() []string {
+	keys := make([]string, 0, len(m.Fields))
+	for k := range m.Fields {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, _ := strconv.ParseFloat(keys[i], 64)
+		b, _ := strconv.ParseFloat(keys[j], 64)
+		return a < b
+	})
+	return keys
+}
+
+func decodeIso(spec *iso8583.MessageSpec, data []byte) (IsoMessage, error) {
+	message := iso8583.NewMessage(spec)
+	err := message.Unpack(data)
+	if err != nil {
+		return IsoMessage{}, err
+	}
+	mti, err := message.GetMTI()
+	if err != nil {
+		return IsoMessage{}, err
+	}
+	isoMessage := IsoMessage{Mti: mti, Fields: make(map[string]string)}
+	for k := range message.GetFields() {
+		if k <= 1 {
+			continue
+		}
+		value, err := message.GetString(k)
+		if err != nil {
+			return IsoMessage{}, err
+		}
+		isoMessage.Fields[strconv.Itoa(k)] = value
+	}
+	return isoMessage, nil
+}
+
+func encodeIso(spec *iso8583.MessageSpec, m IsoMessage) ([]byte, error) {
+	message := iso8583.NewMessage(spec)
+	message.MTI(m.Mti)
+	for k, v := range m.Fields {
+		n, err := strconv.Atoi(k)
+		if err != nil || n <= 1 {
+			return nil, fmt.Errorf("invalid field %s", k)
+		}
+		if _, ok := spec.Fields[n]; !ok {
+			return nil, fmt.Errorf("field %d is not in the spec", n)
+		}
+		err = message.Field(n, v)
+		if err != nil {
+			return nil, fmt.Errorf("field %d: %w", n, err)
+		}
+	}
+	return message.Pack()
+}
+
+// adviceMti returns the advice class of a request MTI, 0200 -> 0220.
+func adviceMti(mti string) (string, error) {
+	if len(mti) != 4 || mti[2] != '0' {
+		return "", fmt.Errorf("MTI %s is not a request", mti)
+	}
+	return mti[:2] + "2" + mti[3:], nil
+}
+
+// repeatMti returns the repeat of an advice MTI, 0420 -> 0421.
+func repeatMti(mti string) string {
+	return mti[:3] + "1"
+}
+
+// responseMti answers a request or its repeat: 1200 -> 1210, 1804 -> 1814,
+// 0421 -> 0430.
+func responseMti(mti string) (string, error) {
+	if len(mti) != 4 {
+		return "", fmt.Errorf("invalid MTI %q", mti)
+	}
+	function := mti[2] - '0'
+	origin := mti[3] - '0'
+	if function > 9 || function%2 == 1 || origin > 9 {
+		return "", fmt.Errorf("MTI %s is not a request", mti)
+	}
+	return mti[:2] + strconv.Itoa(int(function)+1) + strconv.Itoa(int(origin-origin%2)), nil
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// LinkMessage is an unsolicited host frame or the automatic reply to it.
+type LinkMessage struct {
+	Id        int64      `json:"id"`
+	Time      string     `json:"time"`
+	Switch    AtmSwitch  `json:"switch"`
+	Inbound   bool       `json:"inbound"`
+	AutoReply bool       `json:"autoReply"`
+	Message   IsoMessage `json:"message"`
+	Error     string     `json:"error,omitempty"`
+}
+
+type LinkStatus struct {
+	Switch  AtmSwitch `json:"switch"`
+	Remote  string    `json:"remote"`
+	Pending int       `json:"pending"`
+}
+
+// switchLink is a persistent connection to a switch.
+type switchLink struct {
+	target    AtmSwitch
+	atmSwitch atmSwitch
+	framing   framing
+	conn      net.Conn
+	writeMu   sync.Mutex
+	mu        sync.Mutex
+	pending   map[string]chan []byte
+	closeOnce sync.Once
+	done      chan struct{}
+	err       error
+}
+
+type linkManager struct {
+	ctx     context.Context
+	secrets *secretService
+	tunnels *tunnelManager
+	mu      sync.Mutex
+	links   map[AtmSwitch]*switchLink
+	lastId  int64
+}
+
+func newLinkManager(ctx context.Context, secrets *secretService, tunnels *tunnelManager) *linkManager {
+	return &linkManager{
+		ctx:     ctx,
+		secrets: secrets,
+		tunnels: tunnels,
+		links:   make(map[AtmSwitch]*switchLink),
+	}
+}
+
+func isPersistent(target AtmSwitch) bool {
+	return viper.GetBool(fmt.Sprintf("%s_PERSISTENT", target))
+}
+
+// matchKey pairs a request with its response by response MTI and STAN.
+func matchKey(mti string, stan string) string {
+	return mti + ":" + stan
+}
+
+// responseKey is the match key of the response to a request.
+func responseKey(request IsoMessage) (string, error) {
+	mti, err := responseMti(request.Mti)
+	if err != nil {
+		return "", err
+	}
+	return matchKey(mti, request.field(11)), nil
+}
+
+// isNetworkManagement reports requests such as echo tests and key exchanges.
+func isNetworkManagement(mti string) bool {
+	return len(mti) == 4 && mti[1] == '8' && (mti[2]-'0')%2 == 0
+}
+
+// networkManagementApproval uses the action code 800 for the 1993 version.
+func networkManagementApproval(mti string) string {
+	if strings.HasPrefix(mti, "1") {
+		return "800"
+	}
+	return "00"
+}
+
+func (m *linkManager) get(target AtmSwitch, sw atmSwitch) (*switchLink, time.Duration, error) {
+	endpoint, err := getEndpoint(target)
+	if err != nil {
+		return nil, 0, err
+	}
+	m.mu.Lock()
+	link, ok := m.links[target]
+	m.mu.Unlock()
+	if ok {
+		return link, endpoint.timeout, nil
+	}
+
+	d, err := newDialer(m.secrets, m.tunnels)
+	if err != nil {
+		return nil, 0, err
+	}
+	conn, err := endpoint.dial(d)
+	if err != nil {
+		return nil, 0, err
+	}
+	link = &switchLink{
+		target:    target,
+		atmSwitch: sw,
+		framing:   endpoint.framing,
+		conn:      conn,
+		pending:   make(map[string]chan []byte),
+		done:      make(chan struct{}),
+	}
+	m.mu.Lock()
+	if existing, ok := m.links[target]; ok {
+		m.mu.Unlock()
+		conn.Close()
+		return existing, endpoint.timeout, nil
+	}
+	m.links[target] = link
+	m.mu.Unlock()
+	log.Printf("persistent link to %s opened: %s", target, conn.RemoteAddr())
+	go m.dispatch(link)
+	runtime.EventsEmit(m.ctx, "link", m.status())
+	return link, endpoint.timeout, nil
+}
+
+// exchange waits for the response with the same STAN.
+func (m *linkManager) exchange(target AtmSwitch, sw atmSwitch, b []byte) ([]byte, error) {
+	request, err := sw.decode(b)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the request to match its response: %w", err)
+	}
+	key, err := responseKey(request)
+	if err != nil {
+		return nil, err
+	}
+
+	link, timeout, err := m.get(target, sw)
+	if err != nil {
+		return nil, err
+	}
+	response := make(chan []byte, 1)
+	link.mu.Lock()
+	if _, ok := link.pending[key]; ok {
+		link.mu.Unlock()
+		return nil, fmt.Errorf("a request with STAN %s is already outstanding", request.field(11))
+	}
+	link.pending[key] = response
+	link.mu.Unlock()
+	defer func() {
+		link.mu.Lock()
+		delete(link.pending, key)
+		link.mu.Unlock()
+	}()
+
+	frame, err := link.framing.frame(b)
+	if err != nil {
+		return nil, err
+	}
+	err = link.write(frame, timeout)
+	if err != nil {
+		m.close(link, err)
+		return nil, err
+	}
+	select {
+	case frame := <-response:
+		return frame, nil
+	case <-link.done:
+		return nil, fmt.Errorf("link to %s closed: %w", target, link.err)
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("%w from %s for STAN %s", errNoResponse, target, request.field(11))
+	}
+}
+
+func (l *switchLink) write(b []byte, timeout time.Duration) error {
+	l.writeMu.Lock()
+	defer l.writeMu.Unlock()
+	l.conn.SetWriteDeadline(time.Now().Add(timeout))
+	_, err := l.conn.Write(b)
+	return err
+}
+
+func (m *linkManager) dispatch(link *switchLink) {
+	for {
+		frame, err := link.framing.read(link.conn)
+		if err != nil {
+			m.close(link, err)
+			return
+		}
+		message, err := link.atmSwitch.decode(frame)
+		if err != nil {
+			log.Error().Err(err).Msgf("undecodable frame from %s", link.target)
+			m.emit(link.target, true, false, message, err)
+			continue
+		}
+		key := matchKey(message.Mti, message.field(11))
+		link.mu.Lock()
+		response, ok := link.pending[key]
+		if ok {
+			delete(link.pending, key)
+		}
+		link.mu.Unlock()
+		if ok {
+			response <- frame
+			continue
+		}
+
+		log.Printf("unsolicited %s from %s, STAN %s", message.Mti, link.target, message.field(11))
+		m.emit(link.target, true, false, message, nil)
+		if isNetworkManagement(message.Mti) {
+			m.answer(link, message)
+		}
+	}
+}
+
+// answer approves a network management request from the host.
+func (m *linkManager) answer(link *switchLink, request IsoMessage) {
+	response, b, err := networkManagementReply(link.atmSwitch, link.framing, request)
+	if err == nil {
+		err = link.write(b, defaultTimeout)
+	}
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to answer %s from %s", request.Mti, link.target)
+	}
+	m.emit(link.target, false, true, response, err)
+}
+
+func networkManagementReply(sw atmSwitch, f framing, request IsoMessage) (IsoMessage, []byte, error) {
+	response, err := buildResponse(request, networkManagementApproval(request.Mti), "")
+	if err != nil {
+		return response, nil, err
+	}
+	b, err := sw.encode(response)
+	if err != nil {
+		return response, nil, err
+	}
+	b, err = f.frame(b)
+	return response, b, err
+}
+
+func (m *linkManager) close(link *switchLink, err error) {
+	link.closeOnce.Do(func() {
+		link.err = err
+		close(link.done)
+		link.conn.Close()
+		m.mu.Lock()
+		if m.links[link.target] == link {
+			delete(m.links, link.target)
+		}
+		m.mu.Unlock()
+		log.Printf("persistent link to %s closed: %v", link.target, err)
+		runtime.EventsEmit(m.ctx, "link", m.status())
+	})
+}
+
+func (m *linkManager) closeAll() {
+	m.mu.Lock()
+	links := make([]*switchLink, 0, len(m.links))
+	for _, link := range m.links {
+		links = append(links, link)
+	}
+	m.mu.Unlock()
+	for _, link := range links {
+		m.close(link, errors.New("closed by user"))
+	}
+}
+
+func (m *linkManager) status() []LinkStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	statuses := []LinkStatus{}
+	for target, link := range m.links {
+		link.mu.Lock()
+		statuses = append(statuses, LinkStatus{Switch: target, Remote: link.conn.RemoteAddr().String(), Pending: len(link.pending)})
+		link.mu.Unlock()
+	}
+	return statuses
+}
+
+func (m *linkManager) emit(target AtmSwitch, inbound bool, autoReply bool, message IsoMessage, err error) {
+	m.mu.Lock()
+	m.lastId++
+	linkMessage := LinkMessage{
+		Id:        m.lastId,
+		Time:      time.Now().Format(time.RFC3339),
+		Switch:    target,
+		Inbound:   inbound,
+		AutoReply: autoReply,
+		Message:   message,
+	}
+	m.mu.Unlock()
+	if err != nil {
+		linkMessage.Error = err.Error()
+	}
+	runtime.EventsEmit(m.ctx, "link:message", linkMessage)
+}
+
+func (a *App) GetLinks() []LinkStatus {
+	return a.linkManager.status()
+}
+
+func (a *App) CloseLinks() {
+	a.linkManager.closeAll()
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestResponseKey(t *testing.T) {
+	tests := []struct {
+		mti     string
+		want    string
+		wantErr bool
+	}{
+		{mti: "1200", want: "1210:123456"},
+		{mti: "0200", want: "0210:123456"},
+		{mti: "1421", want: "1430:123456"},
+		{mti: "1804", want: "1814:123456"},
+		{mti: "0210", wantErr: true},
+		{mti: "12", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := responseKey(IsoMessage{Mti: tt.mti, Fields: map[string]string{"11": "123456"}})
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("responseKey(%s) = %q, %v, want %q", tt.mti, got, err, tt.want)
+		}
+	}
+
+	b, err := cortex.pack(newTestMessage())
+	if err != nil {
+		t.Fatal(err)
+	}
+	request, err := cortex.decode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := responseKey(request)
+	response, err := buildResponse(request, "000", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err = cortex.encode(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err = cortex.decode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := matchKey(response.Mti, response.field(11)); got != key {
+		t.Errorf("dispatch key = %s, want %s", got, key)
+	}
+}
+
+func TestIsNetworkManagement(t *testing.T) {
+	tests := map[string]bool{
+		"0800": true,
+		"1804": true,
+		"0820": true,
+		"0810": false,
+		"1814": false,
+		"0200": false,
+		"08":   false,
+	}
+	for mti, want := range tests {
+		if got := isNetworkManagement(mti); got != want {
+			t.Errorf("isNetworkManagement(%s) = %v, want %v", mti, got, want)
+		}
+	}
+}
+
+func TestNetworkManagementReply(t *testing.T) {
+	tests := []struct {
+		atmSwitch atmSwitch
+		request   IsoMessage
+		mti       string
+		approval  string
+	}{
+		{cortex, IsoMessage{Mti: "1804", Fields: map[string]string{"7": "1019120000", "11": "000123", "12": "261019120000"}}, "1814", "800"},
+		{narada, IsoMessage{Mti: "0800", Fields: map[string]string{"7": "1019120000", "11": "000123"}}, "0810", "00"},
+	}
+	f := framing{kind: BINARY2_FRAMING}
+	for _, tt := range tests {
+		b, err := tt.atmSwitch.encode(tt.request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		request, err := tt.atmSwitch.decode(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !isNetworkManagement(request.Mti) {
+			t.Errorf("%s is not answered automatically", request.Mti)
+		}
+		_, frame, err := networkManagementReply(tt.atmSwitch, f, request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err = f.read(bytes.NewReader(frame))
+		if err != nil {
+			t.Fatal(err)
+		}
+		response, err := tt.atmSwitch.decode(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if response.Mti != tt.mti || response.field(39) != tt.approval || response.field(11) != "000123" {
+			t.Errorf("reply to %s = %s with field 39 %q and STAN %q, want %s with %q", tt.request.Mti, response.Mti, response.field(39), response.field(11), tt.mti, tt.approval)
+		}
+	}
+}
@@ -2,45 +2,67 @@ package main
 
 import (
 	"fmt"
-	"math/big"
 	"math/rand"
 	"net"
+	"strconv"
 	"time"
 
 	"github.com/jmoiron/sqlx"
 )
 
 type Message struct {
-	Transaction              Transaction `db:"transaction" json:"transaction"`
-	Switch                   AtmSwitch   `db:"switch" json:"switch"`
-	PrimaryAccountNumber     string      `db:"primary_account_number" json:"primaryAccountNumber,omitempty"`
-	TransactionAmount        float64     `db:"transaction_amount" json:"transactionAmount,omitempty"`
-	AcquiringInstitutionCode string      `db:"acquiring_institution_code" json:"acquiringInstitutionCode"`
-	ReceivingInstitutionCode string      `db:"receiving_institution_code" json:"receivingInstitutionCode,omitempty"`
-	TransactionFee           float64     `db:"transaction_fee" json:"transactionFee,omitempty"`
-	TerminalNameAndLocation  string      `db:"terminal_name_location" json:"terminalNameAndLocation"`
-	CurrencyCode             Currency    `db:"currency_code" json:"currencyCode"`
-	TerminalID               string      `db:"terminal_id" json:"terminalId"`
-	SourceAccount            string      `db:"source_account" json:"sourceAccount,omitempty"`
-	DestinationAccount       string      `db:"destination_account" json:"destinationAccount,omitempty"`
-	Channel                  Channel     `db:"channel" json:"channel"`
-	Device                   Device      `db:"device" json:"device"`
-	TargetBank               Bank        `db:"target_bank" json:"targetBank,omitempty"`
-	Id                       int         `db:"id" json:"id,omitempty"`
-	Rrn                      string      `db:"rrn" json:"rrn,omitempty"`
-	TraceNumber              string      `db:"trace_number" json:"traceNumber,omitempty"`
-	TransmissionDateTime     string      `db:"transmission_date_time" json:"transmissionDateTime,omitempty"`
-	LocalTransactionDateTime string      `db:"local_transaction_date_time" json:"localTransactionDateTime,omitempty"`
-	OriginalDataElements     string      `db:"original_data_elements,omitempty" json:"originalDataElements,omitempty"`
-	Mti                      string      `db:"mti" json:"mti,omitempty"`
-	ProcessCode              string      `db:"process_code" json:"processCod,omitempty"`
+	Transaction              Transaction    `db:"transaction" json:"transaction"`
+	Switch                   AtmSwitch      `db:"switch" json:"switch"`
+	PrimaryAccountNumber     string         `db:"primary_account_number" json:"primaryAccountNumber,omitempty"`
+	TransactionAmount        float64        `db:"transaction_amount" json:"transactionAmount,omitempty"`
+	AcquiringInstitutionCode string         `db:"acquiring_institution_code" json:"acquiringInstitutionCode"`
+	ReceivingInstitutionCode string         `db:"receiving_institution_code" json:"receivingInstitutionCode,omitempty"`
+	TransactionFee           float64        `db:"transaction_fee" json:"transactionFee,omitempty"`
+	TerminalNameAndLocation  string         `db:"terminal_name_location" json:"terminalNameAndLocation"`
+	CurrencyCode             Currency       `db:"currency_code" json:"currencyCode"`
+	BillingAmount            float64        `db:"billing_amount" json:"billingAmount,omitempty"`
+	BillingCurrencyCode      Currency       `db:"billing_currency_code" json:"billingCurrencyCode,omitempty"`
+	ConversionRate           float64        `db:"conversion_rate" json:"conversionRate,omitempty"`
+	TerminalID               string         `db:"terminal_id" json:"terminalId"`
+	SourceAccount            string         `db:"source_account" json:"sourceAccount,omitempty"`
+	DestinationAccount       string         `db:"destination_account" json:"destinationAccount,omitempty"`
+	Channel                  Channel        `db:"channel" json:"channel"`
+	Device                   Device         `db:"device" json:"device"`
+	TargetBank               Bank           `db:"target_bank" json:"targetBank,omitempty"`
+	Id                       int            `db:"id" json:"id,omitempty"`
+	Rrn                      string         `db:"rrn" json:"rrn,omitempty"`
+	TraceNumber              string         `db:"trace_number" json:"traceNumber,omitempty"`
+	TransmissionDateTime     string         `db:"transmission_date_time" json:"transmissionDateTime,omitempty"`
+	LocalTransactionDateTime string         `db:"local_transaction_date_time" json:"localTransactionDateTime,omitempty"`
+	OriginalDataElements     string         `db:"original_data_elements,omitempty" json:"originalDataElements,omitempty"`
+	Mti                      string         `db:"mti" json:"mti,omitempty"`
+	ProcessCode              string         `db:"process_code" json:"processCod,omitempty"`
+	ReplacementAmount        float64        `db:"replacement_amount" json:"replacementAmount,omitempty"`
+	ReversalReason           ReversalReason `db:"reversal_reason" json:"reversalReason,omitempty"`
+	ParentId                 int            `db:"parent_id" json:"parentId,omitempty"`
+	ResponseCode             string         `db:"response_code" json:"responseCode,omitempty"`
+	ReversalStatus           ReversalStatus `db:"reversal_status" json:"reversalStatus,omitempty"`
+	ReversalResponseCode     string         `db:"reversal_response_code" json:"reversalResponseCode,omitempty"`
+	AuthorizationId          int            `db:"authorization_id" json:"authorizationId,omitempty"`
+	DepositItems             int            `db:"deposit_items" json:"depositItems,omitempty"`
+	ChequeNumber             string         `db:"cheque_number" json:"chequeNumber,omitempty"`
+	Pin                      string         `db:"-" json:"pin,omitempty"`
+	NewPin                   string         `db:"-" json:"newPin,omitempty"`
+	PinBlock                 string         `db:"-" json:"-"`
+	NewPinBlock              string         `db:"-" json:"-"`
+	ReferenceCode            string         `db:"reference_code" json:"referenceCode,omitempty"`
+	MobileNumber             string         `db:"mobile_number" json:"mobileNumber,omitempty"`
+	Otp                      string         `db:"-" json:"otp,omitempty"`
 }
 
 type AtmResponse struct {
-	TraceNumber  string `json:"traceNumber"`
-	ResponseCode string `json:"responseCode"`
-	Balance      string `json:"balance"`
-	RRN          string `json:"rrn"`
+	TraceNumber   string           `json:"traceNumber"`
+	ResponseCode  string           `json:"responseCode"`
+	Balance       string           `json:"balance"`
+	RRN           string           `json:"rrn"`
+	Statement     []StatementEntry `json:"statement,omitempty"`
+	ReferenceCode string           `json:"referenceCode,omitempty"`
+	Currency      Currency         `json:"currency,omitempty"`
 }
 
 type Currency string
@@ -78,6 +100,19 @@ const (
 	ELOAD    Transaction = "ELOAD"
 	BILLS    Transaction = "BILLS"
 	PURCHASE Transaction = "PURCHASE"
+
+	PRE_AUTH            Transaction = "PRE_AUTH"
+	INCREMENTAL_AUTH    Transaction = "INCREMENTAL_AUTH"
+	PRE_AUTH_COMPLETION Transaction = "PRE_AUTH_COMPLETION"
+	PRE_AUTH_CANCEL     Transaction = "PRE_AUTH_CANCEL"
+
+	DEPOSIT        Transaction = "DEPOSIT"
+	CHEQUE_DEPOSIT Transaction = "CHEQUE_DEPOSIT"
+
+	MINI_STATEMENT Transaction = "MINI_STATEMENT"
+	PIN_CHANGE     Transaction = "PIN_CHANGE"
+
+	CARDLESS_WITHDRAW Transaction = "CARDLESS_WITHDRAW"
 )
 
 type Channel string
@@ -99,11 +134,14 @@ type MTI string
 
 const (
 	FinancialRequestMasterVisa    MTI = "100"
+	AuthorizationRequest          MTI = "100"
 	FinancialRequest              MTI = "200"
 	FinancialAdvice               MTI = "220"
 	FinancialReversal             MTI = "400"
 	FinancialReversalAdvice       MTI = "420"
 	FinancialReversalRepeatAdvice MTI = "421"
+	AcquirerReconciliationRequest MTI = "500"
+	AcquirerReconciliationAdvice  MTI = "520"
 	NetworkManagementRequest      MTI = "800"
 )
 
@@ -123,8 +161,14 @@ func (s *messageService) getMessages(page int) ([]Message, error) {
 	return message, err
 }
 
-func (s *messageService) saveMessage(message Message) error {
-	_, err := s.db.NamedExec(`INSERT INTO atm_message (
+func (s *messageService) getLastMessage(atmSwitch AtmSwitch) (Message, error) {
+	message := Message{}
+	err := s.db.Get(&message, "SELECT * FROM atm_message WHERE switch=$1 ORDER BY id DESC LIMIT 1", atmSwitch)
+	return message, err
+}
+
+func (s *messageService) saveMessage(message Message) (int, error) {
+	result, err := s.db.NamedExec(`INSERT INTO atm_message (
 		mti,
 		"transaction", 
 		primary_account_number, 
@@ -133,6 +177,9 @@ func (s *messageService) saveMessage(message Message) error {
 		receiving_institution_code,
 		terminal_name_location, 
 		currency_code,
+		billing_amount,
+		billing_currency_code,
+		conversion_rate,
 		terminal_id,
 		source_account,
 		destination_account,
@@ -145,22 +192,42 @@ func (s *messageService) saveMessage(message Message) error {
 		local_transaction_date_time,
 		original_data_elements,
 		process_code,
+		replacement_amount,
+		reversal_reason,
+		parent_id,
+		authorization_id,
+		deposit_items,
+		cheque_number,
+		reference_code,
+		mobile_number,
 		switch
 	  ) VALUES (
 		:mti, :transaction, :primary_account_number, :transaction_amount, :acquiring_institution_code, :receiving_institution_code, 
-		:terminal_name_location, :currency_code, :terminal_id, :source_account, :destination_account, :channel, :device, 
-		:target_bank, :rrn, :trace_number, :transmission_date_time, :local_transaction_date_time, :original_data_elements, :process_code, :switch
+		:terminal_name_location, :currency_code, :billing_amount, :billing_currency_code, :conversion_rate,
+		:terminal_id, :source_account, :destination_account, :channel, :device, 
+		:target_bank, :rrn, :trace_number, :transmission_date_time, :local_transaction_date_time, :original_data_elements, :process_code,
+		:replacement_amount, :reversal_reason, :parent_id, :authorization_id,
+		:deposit_items, :cheque_number, :reference_code, :mobile_number, :switch
 	  )`, message)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	return int(id), err
+}
+
+func (s *messageService) saveResponseCode(id int, responseCode string) error {
+	_, err := s.db.Exec("UPDATE atm_message SET response_code=$1 WHERE id=$2", responseCode, id)
 	return err
 }
 
-func (s *messageService) sendTcpMessage(conn net.Conn, packed []byte) error {
-	conn.SetWriteDeadline(time.Now().Add(30 * time.Second))
+func (s *messageService) sendTcpMessage(conn net.Conn, packed []byte, timeout time.Duration) error {
+	conn.SetWriteDeadline(time.Now().Add(timeout))
 	_, err := conn.Write(packed)
 	if err != nil {
 		return err
 	}
-	err = conn.SetReadDeadline(time.Now().Add(30 * time.Second))
+	err = conn.SetReadDeadline(time.Now().Add(timeout))
 	if err != nil {
 		return err
 	}
@@ -190,13 +257,12 @@ func generateLocalTransactionDateTime(transmissionDateTime string) string {
 	return year + transmissionDateTime
 }
 
-func balanceDeserializer(input string) *big.Float {
-	b := new(big.Float)
+func balanceDeserializer(input string) string {
+	var currency Currency
+	var amount int64
 	if len(input) >= 20 {
-		balance := input[8:20]
-		b.SetString(balance)
-		return b.Quo(b, big.NewFloat(100))
+		currency = Currency(input[4:7])
+		amount, _ = strconv.ParseInt(input[8:20], 10, 64)
 	}
-	big.NewFloat(0)
-	return b
+	return formatAmount(fromMinorUnits(amount, currency), currency)
 }
@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE profile (
+  name VARCHAR(50) NOT NULL PRIMARY KEY CHECK (name <> '')
+);
+
+CREATE TABLE profile_config (
+  profile VARCHAR(50) NOT NULL REFERENCES profile (name),
+  "key" VARCHAR(256) NOT NULL CHECK ("key" <> ''),
+  "value" TEXT NOT NULL,
+  PRIMARY KEY (profile, "key")
+);
+
+INSERT INTO profile (name) VALUES ('staging');
+
+INSERT INTO profile_config (profile, "key", "value")
+SELECT 'staging', "key", "value" FROM config;
+
+DELETE FROM config;
+
+INSERT INTO config ("key", "value") VALUES
+('ACTIVE_PROFILE', 'staging');
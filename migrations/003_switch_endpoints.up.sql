@@ -0,0 +1,19 @@
+-- +goose Up
+INSERT INTO profile_config (profile, "key", "value")
+SELECT p.name, k."key", k."value" FROM profile p, (
+  SELECT 'CORTEX_HOST' AS "key", '' AS "value"
+  UNION ALL SELECT 'CORTEX_PORT', ''
+  UNION ALL SELECT 'CORTEX_SECONDARY_HOST', ''
+  UNION ALL SELECT 'CORTEX_SECONDARY_PORT', ''
+  UNION ALL SELECT 'CORTEX_TIMEOUT', '30'
+  UNION ALL SELECT 'NARADA_HOST', ''
+  UNION ALL SELECT 'NARADA_PORT', ''
+  UNION ALL SELECT 'NARADA_SECONDARY_HOST', ''
+  UNION ALL SELECT 'NARADA_SECONDARY_PORT', ''
+  UNION ALL SELECT 'NARADA_TIMEOUT', '30'
+  UNION ALL SELECT 'POSTBRIDGE_HOST', ''
+  UNION ALL SELECT 'POSTBRIDGE_PORT', ''
+  UNION ALL SELECT 'POSTBRIDGE_SECONDARY_HOST', ''
+  UNION ALL SELECT 'POSTBRIDGE_SECONDARY_PORT', ''
+  UNION ALL SELECT 'POSTBRIDGE_TIMEOUT', '30'
+) k;
@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TABLE vault (
+  id INTEGER PRIMARY KEY CHECK (id = 1),
+  salt BLOB NOT NULL,
+  verifier TEXT NOT NULL
+);
+
+INSERT INTO profile_config (profile, "key", "value")
+SELECT name, 'SSH_PRIVATE_KEY', '' FROM profile;
@@ -0,0 +1,7 @@
+-- +goose Up
+INSERT INTO profile_config (profile, "key", "value")
+SELECT p.name, k."key", k."value" FROM profile p, (
+  SELECT 'SSH_AUTH_METHODS' AS "key", 'publickey' AS "value"
+  UNION ALL SELECT 'SSH_PASSWORD', ''
+  UNION ALL SELECT 'SSH_CERTIFICATE', ''
+) k;
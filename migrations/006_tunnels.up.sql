@@ -0,0 +1,32 @@
+-- +goose Up
+CREATE TABLE tunnel (
+  id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+  profile VARCHAR(50) NOT NULL REFERENCES profile (name),
+  name VARCHAR(50) NOT NULL CHECK (name <> ''),
+  jump_hosts TEXT NOT NULL CHECK (jump_hosts <> ''),
+  UNIQUE (profile, name)
+);
+
+CREATE TABLE tunnel_forward (
+  id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+  tunnel_id INTEGER NOT NULL REFERENCES tunnel (id),
+  local_port INTEGER NOT NULL CHECK (local_port > 0),
+  remote_host VARCHAR(256) NOT NULL CHECK (remote_host <> ''),
+  remote_port INTEGER NOT NULL CHECK (remote_port > 0)
+);
+
+INSERT INTO tunnel (profile, name, jump_hosts)
+SELECT p.name, 'bastion', h."value" || ':' || pt."value"
+FROM profile p
+JOIN profile_config h ON h.profile = p.name AND h."key" = 'BASTION_HOST' AND h."value" <> ''
+JOIN profile_config pt ON pt.profile = p.name AND pt."key" = 'BASTION_PORT';
+
+INSERT INTO tunnel_forward (tunnel_id, local_port, remote_host, remote_port)
+SELECT t.id, CAST(lp."value" AS INTEGER), th."value", CAST(rp."value" AS INTEGER)
+FROM tunnel t
+JOIN profile_config lp ON lp.profile = t.profile AND lp."key" = 'SSH_LOCAL_PORT'
+JOIN profile_config th ON th.profile = t.profile AND th."key" = 'TARGET_HOST' AND th."value" <> ''
+JOIN profile_config rp ON rp.profile = t.profile AND rp."key" = 'SSH_REMOTE_PORT';
+
+DELETE FROM profile_config
+WHERE "key" IN ('BASTION_HOST', 'BASTION_PORT', 'TARGET_HOST', 'SSH_LOCAL_PORT', 'SSH_REMOTE_PORT');
@@ -0,0 +1,6 @@
+-- +goose Up
+INSERT INTO profile_config (profile, "key", "value")
+SELECT p.name, k."key", k."value" FROM profile p, (
+  SELECT 'TUNNEL_KEEPALIVE_INTERVAL' AS "key", '15' AS "value"
+  UNION ALL SELECT 'TUNNEL_MAX_BACKOFF', '60'
+) k;
@@ -0,0 +1,9 @@
+-- +goose Up
+INSERT INTO profile_config (profile, "key", "value")
+SELECT p.name, k."key", k."value" FROM profile p, (
+  SELECT 'DIALER' AS "key", 'direct' AS "value"
+  UNION ALL SELECT 'DIALER_TUNNEL', ''
+  UNION ALL SELECT 'PROXY_ADDRESS', ''
+  UNION ALL SELECT 'PROXY_USERNAME', ''
+  UNION ALL SELECT 'PROXY_PASSWORD', ''
+) k;
@@ -0,0 +1,25 @@
+-- +goose Up
+INSERT INTO profile_config (profile, "key", "value")
+SELECT p.name, k."key", k."value" FROM profile p, (
+  SELECT 'CORTEX_TLS' AS "key", 'false' AS "value"
+  UNION ALL SELECT 'CORTEX_TLS_CA', ''
+  UNION ALL SELECT 'CORTEX_TLS_CERT', ''
+  UNION ALL SELECT 'CORTEX_TLS_KEY', ''
+  UNION ALL SELECT 'CORTEX_TLS_SERVER_NAME', ''
+  UNION ALL SELECT 'CORTEX_TLS_MIN_VERSION', '1.2'
+  UNION ALL SELECT 'CORTEX_TLS_PIN', ''
+  UNION ALL SELECT 'NARADA_TLS', 'false'
+  UNION ALL SELECT 'NARADA_TLS_CA', ''
+  UNION ALL SELECT 'NARADA_TLS_CERT', ''
+  UNION ALL SELECT 'NARADA_TLS_KEY', ''
+  UNION ALL SELECT 'NARADA_TLS_SERVER_NAME', ''
+  UNION ALL SELECT 'NARADA_TLS_MIN_VERSION', '1.2'
+  UNION ALL SELECT 'NARADA_TLS_PIN', ''
+  UNION ALL SELECT 'POSTBRIDGE_TLS', 'false'
+  UNION ALL SELECT 'POSTBRIDGE_TLS_CA', ''
+  UNION ALL SELECT 'POSTBRIDGE_TLS_CERT', ''
+  UNION ALL SELECT 'POSTBRIDGE_TLS_KEY', ''
+  UNION ALL SELECT 'POSTBRIDGE_TLS_SERVER_NAME', ''
+  UNION ALL SELECT 'POSTBRIDGE_TLS_MIN_VERSION', '1.2'
+  UNION ALL SELECT 'POSTBRIDGE_TLS_PIN', ''
+) k;
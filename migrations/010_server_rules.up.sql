@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE server_rule (
+  id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+  mti VARCHAR(4) NOT NULL DEFAULT '',
+  process_code VARCHAR(6) NOT NULL DEFAULT '',
+  pan_prefix VARCHAR(19) NOT NULL DEFAULT '',
+  response_code VARCHAR(3) NOT NULL CHECK (response_code <> ''),
+  balance VARCHAR(120) NOT NULL DEFAULT '',
+  delay INTEGER NOT NULL DEFAULT 0 CHECK (delay >= 0)
+);
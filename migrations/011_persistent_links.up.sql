@@ -0,0 +1,7 @@
+-- +goose Up
+INSERT INTO profile_config (profile, "key", "value")
+SELECT p.name, k."key", k."value" FROM profile p, (
+  SELECT 'CORTEX_PERSISTENT' AS "key", 'false' AS "value"
+  UNION ALL SELECT 'NARADA_PERSISTENT', 'false'
+  UNION ALL SELECT 'POSTBRIDGE_PERSISTENT', 'false'
+) k;
@@ -0,0 +1,13 @@
+-- +goose Up
+INSERT INTO profile_config (profile, "key", "value")
+SELECT p.name, k."key", k."value" FROM profile p, (
+  SELECT 'CORTEX_FRAMING' AS "key", 'binary2' AS "value"
+  UNION ALL SELECT 'CORTEX_FRAMING_INCLUSIVE', 'false'
+  UNION ALL SELECT 'CORTEX_TPDU', ''
+  UNION ALL SELECT 'NARADA_FRAMING', 'binary2'
+  UNION ALL SELECT 'NARADA_FRAMING_INCLUSIVE', 'false'
+  UNION ALL SELECT 'NARADA_TPDU', ''
+  UNION ALL SELECT 'POSTBRIDGE_FRAMING', 'binary2'
+  UNION ALL SELECT 'POSTBRIDGE_FRAMING_INCLUSIVE', 'false'
+  UNION ALL SELECT 'POSTBRIDGE_TPDU', ''
+) k;
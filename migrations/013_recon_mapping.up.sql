@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE recon_mapping (
+  switch VARCHAR(50) NOT NULL PRIMARY KEY CHECK (switch <> ''),
+  format VARCHAR(20) NOT NULL CHECK (format IN ('CSV', 'FIXED_WIDTH')),
+  delimiter VARCHAR(1) NOT NULL DEFAULT ',',
+  header_lines INTEGER NOT NULL DEFAULT 0 CHECK (header_lines >= 0),
+  footer_lines INTEGER NOT NULL DEFAULT 0 CHECK (footer_lines >= 0),
+  rrn VARCHAR(20) NOT NULL DEFAULT '',
+  stan VARCHAR(20) NOT NULL DEFAULT '',
+  terminal_id VARCHAR(20) NOT NULL DEFAULT '',
+  amount VARCHAR(20) NOT NULL CHECK (amount <> ''),
+  minor_units BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+INSERT INTO recon_mapping (switch, format, delimiter, header_lines, rrn, stan, terminal_id, amount) VALUES
+('CORTEX', 'CSV', ',', 1, '1', '2', '3', '4'),
+('NARADA', 'CSV', ',', 1, '1', '2', '3', '4'),
+('POSTBRIDGE', 'CSV', ',', 1, '1', '2', '3', '4');
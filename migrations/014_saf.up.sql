@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE saf_item (
+  id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+  message_id INTEGER NOT NULL REFERENCES atm_message (id),
+  switch VARCHAR(50) NOT NULL CHECK (switch <> ''),
+  mti VARCHAR(4) NOT NULL CHECK (mti <> ''),
+  status VARCHAR(20) NOT NULL CHECK (status IN ('PENDING', 'FAILED')),
+  attempts INTEGER NOT NULL DEFAULT 0,
+  last_error TEXT NOT NULL DEFAULT '',
+  created_at VARCHAR(25) NOT NULL,
+  last_attempt_at VARCHAR(25) NOT NULL DEFAULT ''
+);
+
+INSERT INTO profile_config (profile, "key", "value")
+SELECT p.name, k."key", k."value" FROM profile p, (
+  SELECT 'SAF_INTERVAL' AS "key", '30' AS "value"
+  UNION ALL SELECT 'SAF_MAX_ATTEMPTS', '5'
+) k;
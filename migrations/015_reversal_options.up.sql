@@ -0,0 +1,3 @@
+-- +goose Up
+ALTER TABLE atm_message ADD COLUMN replacement_amount VARCHAR(12) NOT NULL DEFAULT '0';
+ALTER TABLE atm_message ADD COLUMN reversal_reason VARCHAR(2) NOT NULL DEFAULT '';
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE atm_message ADD COLUMN parent_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE atm_message ADD COLUMN response_code VARCHAR(3) NOT NULL DEFAULT '';
+ALTER TABLE atm_message ADD COLUMN reversal_status VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE atm_message ADD COLUMN reversal_response_code VARCHAR(3) NOT NULL DEFAULT '';
+
+UPDATE atm_message SET parent_id = COALESCE((
+  SELECT o.id FROM atm_message o
+  WHERE o.switch = atm_message.switch AND o.trace_number = atm_message.trace_number
+  AND o.rrn = atm_message.rrn AND o.id < atm_message.id AND substr(o.mti, 2, 1) <> '4'
+  ORDER BY o.id DESC LIMIT 1
+), 0), "transaction" = substr("transaction", 10)
+WHERE "transaction" LIKE 'REVERSAL %';
@@ -0,0 +1,2 @@
+-- +goose Up
+ALTER TABLE atm_message ADD COLUMN authorization_id INTEGER NOT NULL DEFAULT 0;
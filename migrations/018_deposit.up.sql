@@ -0,0 +1,3 @@
+-- +goose Up
+ALTER TABLE atm_message ADD COLUMN deposit_items INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE atm_message ADD COLUMN cheque_number VARCHAR(20) NOT NULL DEFAULT '';
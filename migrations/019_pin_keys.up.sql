@@ -0,0 +1,7 @@
+-- +goose Up
+INSERT INTO profile_config (profile, "key", "value")
+SELECT p.name, k."key", k."value" FROM profile p, (
+  SELECT 'CORTEX_ZPK' AS "key", '' AS "value"
+  UNION ALL SELECT 'NARADA_ZPK', ''
+  UNION ALL SELECT 'POSTBRIDGE_ZPK', ''
+) k;
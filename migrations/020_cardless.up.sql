@@ -0,0 +1,3 @@
+-- +goose Up
+ALTER TABLE atm_message ADD COLUMN reference_code VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE atm_message ADD COLUMN mobile_number VARCHAR(15) NOT NULL DEFAULT '';
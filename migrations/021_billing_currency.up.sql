@@ -0,0 +1,4 @@
+-- +goose Up
+ALTER TABLE atm_message ADD COLUMN billing_amount VARCHAR(12) NOT NULL DEFAULT '0';
+ALTER TABLE atm_message ADD COLUMN billing_currency_code VARCHAR(3) NOT NULL DEFAULT '';
+ALTER TABLE atm_message ADD COLUMN conversion_rate VARCHAR(12) NOT NULL DEFAULT '0';
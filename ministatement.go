@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// statementEntryLength covers YYMMDD, a 14 character description, C or D
+// and 12 digits of minor units.
+const statementEntryLength = 33
+
+type StatementEntry struct {
+	Date        string  `json:"date"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+	Credit      bool    `json:"credit"`
+}
+
+func isMiniStatement(processCode string) bool {
+	return strings.HasPrefix(processCode, "38")
+}
+
+func parseMiniStatement(data string, currency Currency) ([]StatementEntry, error) {
+	data = strings.TrimRight(data, " ")
+	if len(data)%statementEntryLength != 0 {
+		return nil, fmt.Errorf("mini-statement of %d characters is not made of %d character entries", len(data), statementEntryLength)
+	}
+	entries := []StatementEntry{}
+	for i := 0; i < len(data); i += statementEntryLength {
+		line := data[i : i+statementEntryLength]
+		date := line[:6]
+		if t, err := time.Parse("060102", date); err == nil {
+			date = t.Format("2006-01-02")
+		}
+		sign := line[20]
+		if sign != 'C' && sign != 'D' {
+			return nil, fmt.Errorf("mini-statement entry %d has no C or D sign", i/statementEntryLength+1)
+		}
+		amount, err := strconv.ParseInt(line[21:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("mini-statement entry %d has an invalid amount %q", i/statementEntryLength+1, line[21:])
+		}
+		entries = append(entries, StatementEntry{
+			Date:        date,
+			Description: strings.TrimSpace(line[6:20]),
+			Amount:      fromMinorUnits(amount, currency),
+			Credit:      sign == 'C',
+		})
+	}
+	return entries, nil
+}
+
+// readMiniStatement ignores a mini-statement it cannot parse.
+func readMiniStatement(processCode string, currency Currency, data string) []StatementEntry {
+	if !isMiniStatement(processCode) || len(data) == 0 {
+		return nil
+	}
+	entries, err := parseMiniStatement(data, currency)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return nil
+	}
+	return entries
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMiniStatement(t *testing.T) {
+	data := "261015ATM WITHDRAWALD000000100000" + "261016SALARY        C000002500050   "
+	want := []StatementEntry{
+		{Date: "2026-10-15", Description: "ATM WITHDRAWAL", Amount: 1000},
+		{Date: "2026-10-16", Description: "SALARY", Amount: 25000.5, Credit: true},
+	}
+	got, err := parseMiniStatement(data, PHP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseMiniStatement() = %+v, want %+v", got, want)
+	}
+
+	got, err = parseMiniStatement("261015ATM WITHDRAWALD000000010000", "392")
+	if err != nil || got[0].Amount != 10000 {
+		t.Errorf("parseMiniStatement() in JPY = %+v, %v, want 10000", got, err)
+	}
+
+	for _, invalid := range []string{
+		"261015ATM WITHDRAWALD00000010000",
+		"261015ATM WITHDRAWALX000000100000",
+		"261015ATM WITHDRAWALD0000001000.0",
+	} {
+		if _, err := parseMiniStatement(invalid, PHP); err == nil {
+			t.Errorf("parseMiniStatement(%q) should fail", invalid)
+		}
+	}
+}
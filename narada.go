@@ -3,13 +3,11 @@ package main
 import (
 	"errors"
 	"fmt"
-	"io"
 	"sort"
 
 	"github.com/moov-io/iso8583"
 	"github.com/moov-io/iso8583/encoding"
 	"github.com/moov-io/iso8583/field"
-	"github.com/moov-io/iso8583/network"
 	"github.com/moov-io/iso8583/prefix"
 	"github.com/rs/zerolog/log"
 )
@@ -61,6 +59,12 @@ var naradaSpec = &iso8583.MessageSpec{
 			Enc:         encoding.EBCDIC,
 			Pref:        prefix.EBCDIC.Fixed,
 		}),
+		10: field.NewString(&field.Spec{
+			Length:      8,
+			Description: "Conversion Rate, Cardholder Billing",
+			Enc:         encoding.EBCDIC,
+			Pref:        prefix.EBCDIC.Fixed,
+		}),
 		11: field.NewString(&field.Spec{
 			Length:      6,
 			Description: "Trace Number",
@@ -175,12 +179,24 @@ var naradaSpec = &iso8583.MessageSpec{
 			Enc:         encoding.EBCDIC,
 			Pref:        prefix.EBCDIC.LLL,
 		}),
+		48: field.NewString(&field.Spec{
+			Length:      999,
+			Description: "Additional Data",
+			Enc:         encoding.EBCDIC,
+			Pref:        prefix.EBCDIC.LLL,
+		}),
 		49: field.NewString(&field.Spec{
 			Length:      3,
 			Description: "Transaction Currency Code",
 			Enc:         encoding.EBCDIC,
 			Pref:        prefix.EBCDIC.Fixed,
 		}),
+		50: field.NewString(&field.Spec{
+			Length:      3,
+			Description: "Settlement Currency Code",
+			Enc:         encoding.EBCDIC,
+			Pref:        prefix.EBCDIC.Fixed,
+		}),
 		51: field.NewString(&field.Spec{
 			Length:      3,
 			Description: "Card Holder Currency Code",
@@ -211,12 +227,78 @@ var naradaSpec = &iso8583.MessageSpec{
 			Enc:         encoding.EBCDIC,
 			Pref:        prefix.EBCDIC.LL,
 		}),
+		66: field.NewString(&field.Spec{
+			Length:      1,
+			Description: "Settlement Code",
+			Enc:         encoding.EBCDIC,
+			Pref:        prefix.EBCDIC.Fixed,
+		}),
+		74: field.NewString(&field.Spec{
+			Length:      10,
+			Description: "Credits Number",
+			Enc:         encoding.EBCDIC,
+			Pref:        prefix.EBCDIC.Fixed,
+		}),
+		75: field.NewString(&field.Spec{
+			Length:      10,
+			Description: "Credits Reversal Number",
+			Enc:         encoding.EBCDIC,
+			Pref:        prefix.EBCDIC.Fixed,
+		}),
+		76: field.NewString(&field.Spec{
+			Length:      10,
+			Description: "Debits Number",
+			Enc:         encoding.EBCDIC,
+			Pref:        prefix.EBCDIC.Fixed,
+		}),
+		77: field.NewString(&field.Spec{
+			Length:      10,
+			Description: "Debits Reversal Number",
+			Enc:         encoding.EBCDIC,
+			Pref:        prefix.EBCDIC.Fixed,
+		}),
+		86: field.NewString(&field.Spec{
+			Length:      16,
+			Description: "Credits Amount",
+			Enc:         encoding.EBCDIC,
+			Pref:        prefix.EBCDIC.Fixed,
+		}),
+		87: field.NewString(&field.Spec{
+			Length:      16,
+			Description: "Credits Reversal Amount",
+			Enc:         encoding.EBCDIC,
+			Pref:        prefix.EBCDIC.Fixed,
+		}),
+		88: field.NewString(&field.Spec{
+			Length:      16,
+			Description: "Debits Amount",
+			Enc:         encoding.EBCDIC,
+			Pref:        prefix.EBCDIC.Fixed,
+		}),
+		89: field.NewString(&field.Spec{
+			Length:      16,
+			Description: "Debits Reversal Amount",
+			Enc:         encoding.EBCDIC,
+			Pref:        prefix.EBCDIC.Fixed,
+		}),
 		94: field.NewString(&field.Spec{
 			Length:      2,
 			Description: "Service Indicator",
 			Enc:         encoding.EBCDIC,
 			Pref:        prefix.EBCDIC.Fixed,
 		}),
+		95: field.NewString(&field.Spec{
+			Length:      42,
+			Description: "Replacement Amounts",
+			Enc:         encoding.EBCDIC,
+			Pref:        prefix.EBCDIC.Fixed,
+		}),
+		97: field.NewString(&field.Spec{
+			Length:      17,
+			Description: "Net Settlement Amount",
+			Enc:         encoding.EBCDIC,
+			Pref:        prefix.EBCDIC.Fixed,
+		}),
 		102: field.NewString(&field.Spec{
 			Length:      99,
 			Description: "Source Account",
@@ -236,6 +318,14 @@ func (s *naradaSwitch) getMti(message Message, reversal bool) string {
 	if reversal {
 		return fmt.Sprintf("0%s", FinancialReversal)
 	}
+	switch message.Transaction {
+	case PRE_AUTH, INCREMENTAL_AUTH:
+		return fmt.Sprintf("0%s", AuthorizationRequest)
+	case PRE_AUTH_COMPLETION:
+		return fmt.Sprintf("0%s", FinancialAdvice)
+	case PRE_AUTH_CANCEL:
+		return fmt.Sprintf("0%s", FinancialReversal)
+	}
 	switch message.Channel {
 	case MASTERCARD:
 		return fmt.Sprintf("0%s", FinancialRequestMasterVisa)
@@ -244,17 +334,32 @@ func (s *naradaSwitch) getMti(message Message, reversal bool) string {
 	}
 }
 
-func (s *naradaSwitch) getProcessCode(message Message) string {
+func (s *naradaSwitch) reconciliationMti(advice bool) string {
+	if advice {
+		return fmt.Sprintf("0%s", AcquirerReconciliationAdvice)
+	}
+	return fmt.Sprintf("0%s", AcquirerReconciliationRequest)
+}
+
+func (s *naradaSwitch) getProcessCode(message Message) (string, error) {
 	var processCode string
 	transaction := message.Transaction
 	device := message.Device
 	switch transaction {
-	case PURCHASE, ELOAD:
+	case PURCHASE, ELOAD, PRE_AUTH, INCREMENTAL_AUTH, PRE_AUTH_COMPLETION, PRE_AUTH_CANCEL:
 		processCode = "00"
-	case WITHDRAW:
+	case WITHDRAW, CARDLESS_WITHDRAW:
 		processCode = "01"
 	case IBFTD:
 		processCode = "10"
+	case MINI_STATEMENT:
+		processCode = "38"
+	case PIN_CHANGE:
+		processCode = "96"
+	case DEPOSIT:
+		processCode = "21"
+	case CHEQUE_DEPOSIT:
+		processCode = "24"
 	case IBFTC:
 		if message.TargetBank == INTER_SYSTEM {
 			processCode = "26"
@@ -272,30 +377,37 @@ func (s *naradaSwitch) getProcessCode(message Message) string {
 	case BILLS:
 		processCode = "51"
 	default:
-		panic("Unable to get Process Code")
+		return "", fmt.Errorf("unable to get process code for transaction %s", transaction)
 	}
 
-	return processCode
+	return processCode, nil
 }
 
 var narada = &naradaSwitch{
 	spec: *naradaSpec,
 }
 
-func (s *naradaSwitch) build(message *Message, reversal bool) {
+func (s *naradaSwitch) build(message *Message, reversal bool) error {
 	originalMti := message.Mti
 	message.Mti = s.getMti(*message, reversal)
 	if reversal {
-		originalDataElements := s.serializeOriginalDataElements(originalMti, message.TraceNumber, message.LocalTransactionDateTime, padLeftWithZeros(message.AcquiringInstitutionCode, 10))
+		originalDataElements := s.serializeOriginalDataElements(originalMti, message.TraceNumber, message.LocalTransactionDateTime, message.ReversalReason, padLeftWithZeros(message.AcquiringInstitutionCode, 10))
 		message.OriginalDataElements = originalDataElements
-		message.Transaction = "REVERSAL " + message.Transaction
-		return
+		return nil
+	}
+	if message.AuthorizationId > 0 {
+		message.OriginalDataElements = s.serializeOriginalDataElements(originalMti, message.TraceNumber, message.LocalTransactionDateTime, followUpReason(*message), padLeftWithZeros(message.AcquiringInstitutionCode, 10))
 	}
 	message.TransmissionDateTime = generateTransmissionDateTime()
 	message.TraceNumber = generateStan()
 	message.Rrn = generateRrn()
 	message.LocalTransactionDateTime = generateLocalTransactionDateTime(message.TransmissionDateTime)
-	message.ProcessCode = s.getProcessCode(*message) + "0000"
+	processCode, err := s.getProcessCode(*message)
+	if err != nil {
+		return err
+	}
+	message.ProcessCode = processCode + "0000"
+	return nil
 }
 
 func (s *naradaSwitch) pack(message Message) ([]byte, error) {
@@ -308,9 +420,19 @@ func (s *naradaSwitch) pack(message Message) ([]byte, error) {
 	}
 
 	isoMesage.Field(3, message.ProcessCode)
-	isoMesage.Field(4, padLeftWithZeros(moveDecimalRight(message.TransactionAmount), naradaSpec.Fields[4].Spec().Length))
-	isoMesage.Field(6, padLeftWithZeros(moveDecimalRight(message.TransactionAmount), naradaSpec.Fields[6].Spec().Length))
+	isoMesage.Field(4, padLeftWithZeros(moveDecimalRight(message.TransactionAmount, message.CurrencyCode), naradaSpec.Fields[4].Spec().Length))
+	billing, billingCurrency := billingAmount(message)
+	isoMesage.Field(6, padLeftWithZeros(moveDecimalRight(billing, billingCurrency), naradaSpec.Fields[6].Spec().Length))
 	isoMesage.Field(7, message.TransmissionDateTime)
+
+	if message.ConversionRate > 0 {
+		rate, err := serializeConversionRate(message.ConversionRate)
+		if err != nil {
+			return nil, err
+		}
+		isoMesage.Field(10, rate)
+	}
+
 	isoMesage.Field(11, message.TraceNumber)
 	isoMesage.Field(13, message.LocalTransactionDateTime[2:4]+message.LocalTransactionDateTime[:2])
 	isoMesage.Field(18, string(message.Device))
@@ -325,18 +447,33 @@ func (s *naradaSwitch) pack(message Message) ([]byte, error) {
 		isoMesage.Field(43, string(message.TerminalNameAndLocation))
 	}
 
-	isoMesage.Field(46, padLeftWithZeros(moveDecimalRight(message.TransactionFee), 10))
+	isoMesage.Field(46, padLeftWithZeros(moveDecimalRight(message.TransactionFee, message.CurrencyCode), 10))
+
+	// Narada carries the cardless data in field 63
+	if data := serializeAdditionalData(message); message.Transaction == CARDLESS_WITHDRAW {
+		isoMesage.Field(63, data)
+	} else if len(data) > 0 {
+		isoMesage.Field(48, data)
+	}
 
 	if len(message.CurrencyCode) > 0 {
 		isoMesage.Field(49, string(message.CurrencyCode))
 	}
 
-	isoMesage.Field(51, string(PHP))
+	isoMesage.Field(51, string(billingCurrency))
+
+	if len(message.PinBlock) > 0 {
+		isoMesage.Field(52, message.PinBlock)
+	}
 
 	if len(message.OriginalDataElements) > 0 {
 		isoMesage.Field(56, message.OriginalDataElements)
 	}
 
+	if message.ReplacementAmount > 0 {
+		isoMesage.Field(95, serializeReplacementAmounts(message))
+	}
+
 	if len(message.ReceivingInstitutionCode) > 0 {
 		isoMesage.Field(100, message.ReceivingInstitutionCode)
 	}
@@ -367,26 +504,12 @@ func (s *naradaSwitch) pack(message Message) ([]byte, error) {
 		return nil, err
 	}
 
-	originalLength := len(rawMessage)
-	lengthPrefix := make([]byte, 2)
-	lengthValue := int16(originalLength)
-	lengthPrefix[0] = byte(lengthValue >> 8)
-	lengthPrefix[1] = byte(lengthValue)
-	withPrefix := append(lengthPrefix, rawMessage...)
-	return withPrefix, err
+	return rawMessage, nil
 }
 
-func (s *naradaSwitch) unpack(r io.Reader) (AtmResponse, error) {
-	lengthBuffer := network.NewBinary2BytesHeader()
-	lengthBuffer.ReadFrom(r)
-	length := lengthBuffer.Len
-	response := make([]byte, length)
-	n, err := io.ReadFull(r, response)
-	if err != nil {
-		return AtmResponse{}, err
-	}
-	if n < 2 {
-		return AtmResponse{}, err
+func (s *naradaSwitch) unpack(request Message, response []byte) (AtmResponse, error) {
+	if len(response) < 2 {
+		return AtmResponse{}, errors.New("frame is too short")
 	}
 	responseMessage := iso8583.NewMessage(naradaSpec)
 	responseMessage.Unpack(response)
@@ -395,11 +518,18 @@ func (s *naradaSwitch) unpack(r io.Reader) (AtmResponse, error) {
 	rrn, _ := responseMessage.GetField(37).String()
 	balanceField, _ := responseMessage.GetField(54).String()
 	balance := balanceDeserializer(balanceField)
+	processCode, _ := responseMessage.GetField(3).String()
+	currency, _ := responseMessage.GetField(49).String()
+	additionalData, _ := responseMessage.GetField(48).String()
+	privateData, _ := responseMessage.GetField(63).String()
 	atmResponse := AtmResponse{
-		TraceNumber:  traceNumber,
-		ResponseCode: responseCode,
-		RRN:          rrn,
-		Balance:      fmt.Sprintf("%.2f", balance),
+		TraceNumber:   traceNumber,
+		ResponseCode:  responseCode,
+		RRN:           rrn,
+		Balance:       balance,
+		Statement:     readMiniStatement(processCode, Currency(currency), additionalData),
+		ReferenceCode: readCardlessReference(request.Transaction, processCode, privateData),
+		Currency:      Currency(currency),
 	}
 	keys := make([]int, 0, len(responseMessage.GetFields()))
 
@@ -416,10 +546,18 @@ func (s *naradaSwitch) unpack(r io.Reader) (AtmResponse, error) {
 	return atmResponse, nil
 }
 
-func (s *naradaSwitch) serializeOriginalDataElements(mti string, traceNumber string, localTransactionDateTime string, acquiringCode string) string {
-	return fmt.Sprint(mti, traceNumber, localTransactionDateTime, "01", acquiringCode)
+func (s *naradaSwitch) serializeOriginalDataElements(mti string, traceNumber string, localTransactionDateTime string, reason ReversalReason, acquiringCode string) string {
+	return fmt.Sprint(mti, traceNumber, localTransactionDateTime, reason, acquiringCode)
 }
 
 func (s *naradaSwitch) packEchoTest() ([]byte, error) {
 	return nil, errors.New("echo test not supported")
 }
+
+func (s *naradaSwitch) decode(frame []byte) (IsoMessage, error) {
+	return decodeIso(naradaSpec, frame)
+}
+
+func (s *naradaSwitch) encode(message IsoMessage) ([]byte, error) {
+	return encodeIso(naradaSpec, message)
+}
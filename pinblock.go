@@ -0,0 +1,91 @@
+package main
+
+import (
+	"crypto/des"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const (
+	minPinLength = 4
+	maxPinLength = 12
+)
+
+// formatPinBlock builds an ISO 9564 format 0 PIN block.
+func formatPinBlock(pin string, pan string) (string, error) {
+	if len(pin) < minPinLength || len(pin) > maxPinLength || !isNumeric(pin) {
+		return "", fmt.Errorf("PIN must be %d to %d digits", minPinLength, maxPinLength)
+	}
+	if len(pan) < 13 || !isNumeric(pan) {
+		return "", fmt.Errorf("a PIN block needs a primary account number of at least 13 digits")
+	}
+	pinField, err := hex.DecodeString(fmt.Sprintf("0%X%s", len(pin), pin) + strings.Repeat("F", 14-len(pin)))
+	if err != nil {
+		return "", err
+	}
+	panField, err := hex.DecodeString("0000" + pan[len(pan)-13:len(pan)-1])
+	if err != nil {
+		return "", err
+	}
+	for i := range pinField {
+		pinField[i] ^= panField[i]
+	}
+	return strings.ToUpper(hex.EncodeToString(pinField)), nil
+}
+
+// encryptPinBlock takes a double or triple length TDES key in hex.
+func encryptPinBlock(block string, zpk string) (string, error) {
+	key, err := hex.DecodeString(zpk)
+	if err != nil || (len(key) != 16 && len(key) != 24) {
+		return "", fmt.Errorf("zone PIN key must be 32 or 48 hex digits")
+	}
+	if len(key) == 16 {
+		key = append(key, key[:8]...)
+	}
+	cipher, err := des.NewTripleDESCipher(key)
+	if err != nil {
+		return "", err
+	}
+	clear, err := hex.DecodeString(block)
+	if err != nil {
+		return "", err
+	}
+	encrypted := make([]byte, len(clear))
+	cipher.Encrypt(encrypted, clear)
+	return strings.ToUpper(hex.EncodeToString(encrypted)), nil
+}
+
+// encodePinBlocks encrypts the blocks under <SWITCH>_ZPK when one is set.
+// PINs and blocks are not stored, so reversals and advice repeats, which are
+// rebuilt from the database, are sent without field 52.
+func encodePinBlocks(secrets *secretService, message *Message) error {
+	if len(message.Pin) == 0 && len(message.NewPin) == 0 {
+		return nil
+	}
+	zpk, err := secrets.get(fmt.Sprintf("%s_ZPK", message.Switch))
+	if err != nil {
+		return err
+	}
+	encode := func(pin string) (string, error) {
+		if len(pin) == 0 {
+			return "", nil
+		}
+		block, err := formatPinBlock(pin, message.PrimaryAccountNumber)
+		if err != nil || len(zpk) == 0 {
+			return block, err
+		}
+		return encryptPinBlock(block, zpk)
+	}
+	message.PinBlock, err = encode(message.Pin)
+	if err != nil {
+		return err
+	}
+	message.NewPinBlock, err = encode(message.NewPin)
+	if err != nil {
+		return err
+	}
+	message.Pin = ""
+	message.NewPin = ""
+	return nil
+}
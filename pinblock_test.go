@@ -0,0 +1,59 @@
+package main
+
+import "testing"
+
+func TestFormatPinBlock(t *testing.T) {
+	tests := []struct {
+		name    string
+		pin     string
+		pan     string
+		want    string
+		wantErr bool
+	}{
+		{name: "4 digit PIN", pin: "1234", pan: "4111111111111111", want: "041225EEEEEEEEEE"},
+		{name: "6 digit PIN", pin: "123456", pan: "4111111111111111", want: "06122547EEEEEEEE"},
+		{name: "12 digit PIN", pin: "123456789012", pan: "5432101234567890", want: "0C1215575BD57576"},
+		{name: "19 digit PAN", pin: "1234", pan: "4000123456789012345", want: "041200A9876FEDCB"},
+		{name: "short PIN", pin: "123", pan: "4111111111111111", wantErr: true},
+		{name: "long PIN", pin: "1234567890123", pan: "4111111111111111", wantErr: true},
+		{name: "PIN not numeric", pin: "12a4", pan: "4111111111111111", wantErr: true},
+		{name: "short PAN", pin: "1234", pan: "411111111111", wantErr: true},
+		{name: "PAN not numeric", pin: "1234", pan: "41111111111111x1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatPinBlock(tt.pin, tt.pan)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("formatPinBlock() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("formatPinBlock() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncryptPinBlock(t *testing.T) {
+	tests := []struct {
+		name    string
+		zpk     string
+		want    string
+		wantErr bool
+	}{
+		{name: "double length key", zpk: "0123456789ABCDEFFEDCBA9876543210", want: "2A3D408A1977DDE9"},
+		{name: "triple length key", zpk: "0123456789ABCDEFFEDCBA987654321089ABCDEF01234567", want: "6A953D63752E5E1B"},
+		{name: "single length key", zpk: "0123456789ABCDEF", wantErr: true},
+		{name: "key not hex", zpk: "0123456789ABCDEFFEDCBA987654321G", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := encryptPinBlock("041225EEEEEEEEEE", tt.zpk)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("encryptPinBlock() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("encryptPinBlock() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
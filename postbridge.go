@@ -3,9 +3,10 @@ package main
 import (
 	"encoding/xml"
 	"fmt"
-	"io"
+	"reflect"
+	"strconv"
+	"strings"
 
-	"github.com/moov-io/iso8583/network"
 	"github.com/rs/zerolog/log"
 )
 
@@ -14,51 +15,84 @@ type postbridgeSwitch struct {
 
 var postbridge = &postbridgeSwitch{}
 
-func (s *postbridgeSwitch) build(message *Message, reversal bool) {
+func (s *postbridgeSwitch) build(message *Message, reversal bool) error {
 	originalMti := message.Mti
 	message.Mti = s.getMti(*message, reversal)
 	if reversal {
-		originalDataElements := s.serializeOriginalDataElements(originalMti, message.TraceNumber, message.TransmissionDateTime, padLeftWithZeros(message.AcquiringInstitutionCode, 10))
+		originalDataElements := s.serializeOriginalDataElements(originalMti, message.TraceNumber, message.TransmissionDateTime, message.ReversalReason, padLeftWithZeros(message.AcquiringInstitutionCode, 10))
 		message.OriginalDataElements = originalDataElements
-		message.Transaction = "REVERSAL " + message.Transaction
-		return
+		return nil
+	}
+	if message.AuthorizationId > 0 {
+		message.OriginalDataElements = s.serializeOriginalDataElements(originalMti, message.TraceNumber, message.TransmissionDateTime, followUpReason(*message), padLeftWithZeros(message.AcquiringInstitutionCode, 10))
 	}
 	message.TransmissionDateTime = generateTransmissionDateTime()
 	message.TraceNumber = generateStan()
 	message.Rrn = generateRrn()
 	message.LocalTransactionDateTime = generateLocalTransactionDateTime(message.TransmissionDateTime)
-	message.ProcessCode = s.getProcessCode(*message) + "0000"
+	processCode, err := s.getProcessCode(*message)
+	if err != nil {
+		return err
+	}
+	message.ProcessCode = processCode + "0000"
+	return nil
 }
 
 func (s *postbridgeSwitch) pack(message Message) ([]byte, error) {
 	t := message.TransmissionDateTime
 	l := message.LocalTransactionDateTime
+	var replacementAmounts string
+	if message.ReplacementAmount > 0 {
+		replacementAmounts = serializeReplacementAmounts(message)
+	}
+	var conversionRate string
+	if message.ConversionRate > 0 {
+		rate, err := serializeConversionRate(message.ConversionRate)
+		if err != nil {
+			return nil, err
+		}
+		conversionRate = rate
+	}
+	billing, billingCurrency := billingAmount(message)
+	additionalData := serializeAdditionalData(message)
+	var privateData string
+	if message.Transaction == CARDLESS_WITHDRAW {
+		// Postbridge carries the cardless data in field 125
+		privateData, additionalData = additionalData, ""
+	}
 	iso := Iso8583PostXml{
 		MsgType: message.Mti,
 		Fields: &Fields{
 			Field002: message.PrimaryAccountNumber,
 			Field003: message.ProcessCode,
-			Field004: padLeftWithZeros(moveDecimalRight(message.TransactionAmount), 12),
+			Field004: padLeftWithZeros(moveDecimalRight(message.TransactionAmount, message.CurrencyCode), 12),
+			Field006: padLeftWithZeros(moveDecimalRight(billing, billingCurrency), 12),
 			Field007: t,
+			Field010: conversionRate,
 			Field011: message.TraceNumber,
 			Field012: t[4:],
 			Field013: t[:4],
 			Field014: fmt.Sprintf("%02s%02s", l[:2], l[2:4]),
 			Field015: t[0:4],
 			Field018: string(message.Device),
-			Field028: "D" + padLeftWithZeros(moveDecimalRight(message.TransactionFee), 8),
+			Field028: "D" + padLeftWithZeros(moveDecimalRight(message.TransactionFee, message.CurrencyCode), 8),
 			Field032: padLeftWithZeros(message.AcquiringInstitutionCode, 10),
 			Field037: message.Rrn,
 			Field041: message.TerminalID,
 			Field043: message.TerminalNameAndLocation,
+			Field048: additionalData,
 			Field049: string(message.CurrencyCode),
+			Field051: string(billingCurrency),
+			Field052: message.PinBlock,
 			Field090: message.OriginalDataElements,
+			Field095: replacementAmounts,
 			Field100: message.ReceivingInstitutionCode,
 			Field102: message.SourceAccount,
 			Field103: message.DestinationAccount,
+			Field125: privateData,
 			Field127025: &IccData{
 				IccRequest: &IccRequestType{
-					AmountAuthorized: padLeftWithZeros(moveDecimalRight(message.TransactionAmount), 12),
+					AmountAuthorized: padLeftWithZeros(moveDecimalRight(message.TransactionAmount, message.CurrencyCode), 12),
 				},
 			},
 		},
@@ -69,39 +103,25 @@ func (s *postbridgeSwitch) pack(message Message) ([]byte, error) {
 	}
 	withHeader := xml.Header + string(xmlData)
 	log.Print(withHeader)
-	originalLength := len(withHeader)
-	lengthPrefix := make([]byte, 2)
-	lengthValue := int16(originalLength)
-	lengthPrefix[0] = byte(lengthValue >> 8)
-	lengthPrefix[1] = byte(lengthValue)
-	withPrefix := append(lengthPrefix, withHeader...)
-	return withPrefix, nil
+	return []byte(withHeader), nil
 }
 
-func (s *postbridgeSwitch) unpack(r io.Reader) (AtmResponse, error) {
-	lengthBuffer := network.NewBinary2BytesHeader()
-	lengthBuffer.ReadFrom(r)
-	length := lengthBuffer.Len
-	response := make([]byte, length)
-	n, err := io.ReadFull(r, response)
+func (s *postbridgeSwitch) unpack(request Message, response []byte) (AtmResponse, error) {
 	log.Printf("%v", string(response))
 	var iso8583PostXml Iso8583PostXml
-	if err != nil {
-		return AtmResponse{}, err
-	}
-	if n < 2 {
-		return AtmResponse{}, err
-	}
-	err = xml.Unmarshal(response, &iso8583PostXml)
+	err := xml.Unmarshal(response, &iso8583PostXml)
 	if err != nil {
 		return AtmResponse{}, err
 	}
 	balance := balanceDeserializer(iso8583PostXml.Fields.Field054)
 	return AtmResponse{
-		Balance:      fmt.Sprintf("%.2f", balance),
-		TraceNumber:  iso8583PostXml.Fields.Field011,
-		ResponseCode: iso8583PostXml.Fields.Field039,
-		RRN:          iso8583PostXml.Fields.Field037,
+		Balance:       balance,
+		TraceNumber:   iso8583PostXml.Fields.Field011,
+		ResponseCode:  iso8583PostXml.Fields.Field039,
+		RRN:           iso8583PostXml.Fields.Field037,
+		Statement:     readMiniStatement(iso8583PostXml.Fields.Field003, Currency(iso8583PostXml.Fields.Field049), iso8583PostXml.Fields.Field125),
+		ReferenceCode: readCardlessReference(request.Transaction, iso8583PostXml.Fields.Field003, iso8583PostXml.Fields.Field125),
+		Currency:      Currency(iso8583PostXml.Fields.Field049),
 	}, nil
 }
 
@@ -122,24 +142,26 @@ func (s *postbridgeSwitch) packEchoTest() ([]byte, error) {
 		return nil, err
 	}
 	withHeader := xml.Header + string(xmlData)
-	originalLength := len(withHeader)
-	lengthPrefix := make([]byte, 2)
-	lengthValue := int16(originalLength)
-	lengthPrefix[0] = byte(lengthValue >> 8)
-	lengthPrefix[1] = byte(lengthValue)
-	withPrefix := append(lengthPrefix, withHeader...)
-	return withPrefix, nil
+	return []byte(withHeader), nil
 }
 
-func (s *postbridgeSwitch) getProcessCode(message Message) string {
+func (s *postbridgeSwitch) getProcessCode(message Message) (string, error) {
 	var processCode string
 	transaction := message.Transaction
 	device := message.Device
 	switch transaction {
-	case PURCHASE:
+	case PURCHASE, PRE_AUTH, INCREMENTAL_AUTH, PRE_AUTH_COMPLETION, PRE_AUTH_CANCEL:
 		processCode = "00"
-	case WITHDRAW, IBFTD, ELOAD:
+	case WITHDRAW, IBFTD, ELOAD, CARDLESS_WITHDRAW:
 		processCode = "01"
+	case MINI_STATEMENT:
+		processCode = "38"
+	case PIN_CHANGE:
+		processCode = "97"
+	case DEPOSIT:
+		processCode = "21"
+	case CHEQUE_DEPOSIT:
+		processCode = "24"
 	case IBFTC:
 		if message.TargetBank == INTER_SYSTEM {
 			processCode = "26"
@@ -157,16 +179,24 @@ func (s *postbridgeSwitch) getProcessCode(message Message) string {
 	case BILLS:
 		processCode = "50"
 	default:
-		panic("Unable to get Process Code")
+		return "", fmt.Errorf("unable to get process code for transaction %s", transaction)
 	}
 
-	return processCode
+	return processCode, nil
 }
 
 func (s *postbridgeSwitch) getMti(message Message, reversal bool) string {
 	if reversal {
 		return fmt.Sprintf("0%s", FinancialReversal)
 	}
+	switch message.Transaction {
+	case PRE_AUTH, INCREMENTAL_AUTH:
+		return fmt.Sprintf("0%s", AuthorizationRequest)
+	case PRE_AUTH_COMPLETION:
+		return fmt.Sprintf("0%s", FinancialAdvice)
+	case PRE_AUTH_CANCEL:
+		return fmt.Sprintf("0%s", FinancialReversal)
+	}
 	switch message.Channel {
 	case MASTERCARD:
 		return fmt.Sprintf("0%s", FinancialRequestMasterVisa)
@@ -175,6 +205,75 @@ func (s *postbridgeSwitch) getMti(message Message, reversal bool) string {
 	}
 }
 
-func (s *postbridgeSwitch) serializeOriginalDataElements(mti string, traceNumber string, transmissionDateTime string, acquiringCode string) string {
-	return fmt.Sprint(mti, traceNumber, transmissionDateTime, "01", acquiringCode)
+func (s *postbridgeSwitch) reconciliationMti(advice bool) string {
+	if advice {
+		return fmt.Sprintf("0%s", AcquirerReconciliationAdvice)
+	}
+	return fmt.Sprintf("0%s", AcquirerReconciliationRequest)
+}
+
+func (s *postbridgeSwitch) serializeOriginalDataElements(mti string, traceNumber string, transmissionDateTime string, reason ReversalReason, acquiringCode string) string {
+	return fmt.Sprint(mti, traceNumber, transmissionDateTime, reason, acquiringCode)
+}
+
+// postbridgeFieldIndex maps field keys such as "39" or "127.2" to the string
+// fields of Fields.
+var postbridgeFieldIndex = func() map[string]int {
+	index := make(map[string]int)
+	t := reflect.TypeOf(Fields{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Type.Kind() != reflect.String {
+			continue
+		}
+		var parts []string
+		for _, p := range strings.Split(strings.TrimPrefix(f.Tag.Get("xml"), "Field_"), "_") {
+			n, err := strconv.Atoi(p)
+			if err != nil {
+				parts = nil
+				break
+			}
+			parts = append(parts, strconv.Itoa(n))
+		}
+		if len(parts) > 0 {
+			index[strings.Join(parts, ".")] = i
+		}
+	}
+	return index
+}()
+
+func (s *postbridgeSwitch) decode(frame []byte) (IsoMessage, error) {
+	var iso8583PostXml Iso8583PostXml
+	err := xml.Unmarshal(frame, &iso8583PostXml)
+	if err != nil {
+		return IsoMessage{}, err
+	}
+	message := IsoMessage{Mti: iso8583PostXml.MsgType, Fields: make(map[string]string)}
+	if iso8583PostXml.Fields == nil {
+		return message, nil
+	}
+	v := reflect.ValueOf(iso8583PostXml.Fields).Elem()
+	for key, i := range postbridgeFieldIndex {
+		if value := v.Field(i).String(); len(value) > 0 {
+			message.Fields[key] = value
+		}
+	}
+	return message, nil
+}
+
+func (s *postbridgeSwitch) encode(message IsoMessage) ([]byte, error) {
+	fields := &Fields{}
+	v := reflect.ValueOf(fields).Elem()
+	for key, value := range message.Fields {
+		i, ok := postbridgeFieldIndex[key]
+		if !ok {
+			return nil, fmt.Errorf("field %s is not in the Postilion schema", key)
+		}
+		v.Field(i).SetString(value)
+	}
+	xmlData, err := xml.MarshalIndent(Iso8583PostXml{MsgType: message.Mti, Fields: fields}, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	return []byte(xml.Header + string(xmlData)), nil
 }
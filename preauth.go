@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+)
+
+// NO_REASON is the reason of follow-ups that are not cancellations.
+const NO_REASON ReversalReason = "00"
+
+// PreAuthFollowUp carries the additional amount of an incremental
+// authorization or the final amount of a completion.
+type PreAuthFollowUp struct {
+	Transaction Transaction `json:"transaction"`
+	Amount      float64     `json:"amount"`
+}
+
+// PreAuth is a pre-auth with its follow-ups, oldest first.
+type PreAuth struct {
+	PreAuth    Message   `json:"preAuth"`
+	FollowUps  []Message `json:"followUps"`
+	Authorized float64   `json:"authorized"`
+	Closed     bool      `json:"closed"`
+}
+
+func isFollowUp(transaction Transaction) bool {
+	switch transaction {
+	case INCREMENTAL_AUTH, PRE_AUTH_COMPLETION, PRE_AUTH_CANCEL:
+		return true
+	}
+	return false
+}
+
+// isHold tells whether a transaction only holds funds.
+func isHold(transaction Transaction) bool {
+	switch transaction {
+	case PRE_AUTH, INCREMENTAL_AUTH, PRE_AUTH_CANCEL:
+		return true
+	}
+	return false
+}
+
+func followUpReason(message Message) ReversalReason {
+	if message.Transaction == PRE_AUTH_CANCEL {
+		return CUSTOMER_CANCELLATION
+	}
+	return NO_REASON
+}
+
+// getPreAuth sums the approved holds and closes the pre-auth once it is
+// completed, cancelled or reversed.
+func (s *messageService) getPreAuth(id int) (PreAuth, error) {
+	preAuth, err := s.getMessage(id)
+	if err != nil {
+		return PreAuth{}, err
+	}
+	if preAuth.Transaction != PRE_AUTH {
+		return PreAuth{}, fmt.Errorf("message %d is not a pre-auth", id)
+	}
+	followUps := []Message{}
+	err = s.db.Select(&followUps, "SELECT * FROM atm_message WHERE authorization_id=$1 ORDER BY id", id)
+	if err != nil {
+		return PreAuth{}, err
+	}
+	result := PreAuth{PreAuth: preAuth, FollowUps: followUps}
+	if !approved(preAuth.ResponseCode) {
+		return result, nil
+	}
+	authorized := minorUnits(preAuth.TransactionAmount, preAuth.CurrencyCode)
+	result.Closed = preAuth.ReversalStatus == REVERSAL_APPROVED
+	for _, f := range followUps {
+		if f.ParentId > 0 || !approved(f.ResponseCode) || f.ReversalStatus == REVERSAL_APPROVED {
+			continue
+		}
+		switch f.Transaction {
+		case INCREMENTAL_AUTH:
+			authorized += minorUnits(f.TransactionAmount, preAuth.CurrencyCode)
+		case PRE_AUTH_COMPLETION, PRE_AUTH_CANCEL:
+			result.Closed = true
+		}
+	}
+	result.Authorized = fromMinorUnits(authorized, preAuth.CurrencyCode)
+	return result, nil
+}
+
+// newFollowUp copies an open pre-auth into a follow-up that references it.
+func newFollowUp(preAuth PreAuth, followUp PreAuthFollowUp) (Message, error) {
+	if !isFollowUp(followUp.Transaction) {
+		return Message{}, fmt.Errorf("%s is not a pre-auth follow-up", followUp.Transaction)
+	}
+	if !approved(preAuth.PreAuth.ResponseCode) {
+		return Message{}, fmt.Errorf("pre-auth %d was not approved", preAuth.PreAuth.Id)
+	}
+	if preAuth.Closed {
+		return Message{}, fmt.Errorf("pre-auth %d is already completed or cancelled", preAuth.PreAuth.Id)
+	}
+	message := preAuth.PreAuth
+	message.BillingAmount = 0
+	switch followUp.Transaction {
+	case INCREMENTAL_AUTH:
+		if followUp.Amount <= 0 {
+			return Message{}, fmt.Errorf("incremental amount is required")
+		}
+		message.TransactionAmount = followUp.Amount
+	case PRE_AUTH_COMPLETION:
+		if followUp.Amount <= 0 {
+			return Message{}, fmt.Errorf("completion amount is required")
+		}
+		if minorUnits(followUp.Amount, message.CurrencyCode) > minorUnits(preAuth.Authorized, message.CurrencyCode) {
+			return Message{}, fmt.Errorf("completion amount exceeds the authorized amount %.2f", preAuth.Authorized)
+		}
+		message.TransactionAmount = followUp.Amount
+	case PRE_AUTH_CANCEL:
+		message.TransactionAmount = preAuth.Authorized
+	}
+	message.Transaction = followUp.Transaction
+	message.AuthorizationId = preAuth.PreAuth.Id
+	message.ParentId = 0
+	message.ResponseCode = ""
+	message.ReversalStatus = ""
+	message.ReversalResponseCode = ""
+	message.ReplacementAmount = 0
+	message.ReversalReason = ""
+	message.OriginalDataElements = ""
+	return message, nil
+}
+
+func (a *App) GetPreAuth(id int) (PreAuth, error) {
+	preAuth, err := a.messageService.getPreAuth(id)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return PreAuth{}, err
+	}
+	return preAuth, nil
+}
+
+func (a *App) SendPreAuthFollowUp(id int, followUp PreAuthFollowUp) (AtmResponse, error) {
+	preAuth, err := a.messageService.getPreAuth(id)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return AtmResponse{}, err
+	}
+	message, err := newFollowUp(preAuth, followUp)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return AtmResponse{}, err
+	}
+	return sendMessage(a, message, false)
+}
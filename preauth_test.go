@@ -0,0 +1,91 @@
+package main
+
+import "testing"
+
+func TestPreAuthFollowUp(t *testing.T) {
+	s := &messageService{db: newTestDB(t)}
+	save := func(message Message) int {
+		id, err := s.saveMessage(message)
+		if err == nil {
+			err = s.saveResponseCode(id, message.ResponseCode)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	preAuth := newTestMessage()
+	preAuth.Transaction, preAuth.Device, preAuth.Mti, preAuth.ResponseCode = PRE_AUTH, POS, "1100", "000"
+	id := save(preAuth)
+	for _, f := range []struct {
+		amount       float64
+		responseCode string
+	}{{200, "000"}, {300, "116"}} {
+		incremental := newTestMessage()
+		incremental.Transaction, incremental.AuthorizationId = INCREMENTAL_AUTH, id
+		incremental.TransactionAmount, incremental.ResponseCode = f.amount, f.responseCode
+		save(incremental)
+	}
+
+	p, err := s.getPreAuth(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Authorized != 1200 || p.Closed {
+		t.Fatalf("getPreAuth() = %v authorized, closed %v, want 1200 and open", p.Authorized, p.Closed)
+	}
+	tests := []struct {
+		followUp PreAuthFollowUp
+		amount   float64
+		wantErr  bool
+	}{
+		{followUp: PreAuthFollowUp{PRE_AUTH_COMPLETION, 1200}, amount: 1200},
+		{followUp: PreAuthFollowUp{PRE_AUTH_COMPLETION, 950.5}, amount: 950.5},
+		{followUp: PreAuthFollowUp{PRE_AUTH_COMPLETION, 1200.01}, wantErr: true},
+		{followUp: PreAuthFollowUp{PRE_AUTH_COMPLETION, 0}, wantErr: true},
+		{followUp: PreAuthFollowUp{INCREMENTAL_AUTH, 100}, amount: 100},
+		{followUp: PreAuthFollowUp{PRE_AUTH_CANCEL, 0}, amount: 1200},
+		{followUp: PreAuthFollowUp{PURCHASE, 100}, wantErr: true},
+	}
+	for _, tt := range tests {
+		message, err := newFollowUp(p, tt.followUp)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("newFollowUp(%+v) error = %v, wantErr %v", tt.followUp, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (message.TransactionAmount != tt.amount || message.AuthorizationId != id || message.Transaction != tt.followUp.Transaction) {
+			t.Errorf("newFollowUp(%+v) = %v for pre-auth %d, want %v for %d", tt.followUp, message.TransactionAmount, message.AuthorizationId, tt.amount, id)
+		}
+	}
+
+	cancel, _ := newFollowUp(p, PreAuthFollowUp{Transaction: PRE_AUTH_CANCEL})
+	if err := cortex.build(&cancel, false); err != nil {
+		t.Fatal(err)
+	}
+	if want := "1100123456261019120000" + string(CUSTOMER_CANCELLATION) + "0000001234"; cancel.OriginalDataElements != want {
+		t.Errorf("cancel original data elements = %s, want %s", cancel.OriginalDataElements, want)
+	}
+
+	completion, _ := newFollowUp(p, PreAuthFollowUp{PRE_AUTH_COMPLETION, 1000})
+	completion.ResponseCode = "000"
+	save(completion)
+	p, err = s.getPreAuth(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Closed {
+		t.Error("getPreAuth() after an approved completion should be closed")
+	}
+	if _, err := newFollowUp(p, PreAuthFollowUp{PRE_AUTH_COMPLETION, 100}); err == nil {
+		t.Error("newFollowUp() of a completed pre-auth should fail")
+	}
+
+	preAuth.ResponseCode = "116"
+	p, err = s.getPreAuth(save(preAuth))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newFollowUp(p, PreAuthFollowUp{PRE_AUTH_CANCEL, 0}); err == nil {
+		t.Error("newFollowUp() of a declined pre-auth should fail")
+	}
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+type Profile struct {
+	Name    string   `db:"name" json:"name"`
+	Configs []Config `json:"configs"`
+	Tunnels []Tunnel `json:"tunnels,omitempty"`
+}
+
+func (s *configService) getProfiles() ([]Profile, error) {
+	profiles := []Profile{}
+	err := s.db.Select(&profiles, "SELECT name FROM profile ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	for i := range profiles {
+		configs, err := s.getProfileConfigs(profiles[i].Name)
+		if err != nil {
+			return nil, err
+		}
+		profiles[i].Configs = configs
+	}
+	return profiles, nil
+}
+
+func (s *configService) switchProfile(name string) error {
+	configs, err := s.getProfileConfigs(name)
+	if err != nil {
+		return err
+	}
+	if len(configs) == 0 {
+		return fmt.Errorf("profile %q does not exist", name)
+	}
+	_, err = s.db.Exec(`UPDATE config SET "value" = $1 WHERE "key" = $2`, name, activeProfileKey)
+	if err != nil {
+		return err
+	}
+	viper.SetDefault(activeProfileKey, name)
+	for _, c := range configs {
+		viper.SetDefault(c.Key, c.Value)
+	}
+	return nil
+}
+
+// createProfile copies every setting of an existing profile into a new one.
+func (s *configService) createProfile(name string, from string) error {
+	if len(name) == 0 {
+		return errors.New("profile name is required")
+	}
+	exists, err := s.profileExists(from)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("profile %q does not exist", from)
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("INSERT INTO profile (name) VALUES ($1)", name)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to create profile %q: %w", name, err)
+	}
+	_, err = tx.Exec(`INSERT INTO profile_config (profile, "key", "value")
+		SELECT $1, "key", "value" FROM profile_config WHERE profile = $2 ORDER BY rowid`, name, from)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	_, err = tx.Exec("INSERT INTO tunnel (profile, name, jump_hosts) SELECT $1, name, jump_hosts FROM tunnel WHERE profile = $2", name, from)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO tunnel_forward (tunnel_id, local_port, remote_host, remote_port)
+		SELECT n.id, f.local_port, f.remote_host, f.remote_port FROM tunnel_forward f
+		JOIN tunnel o ON o.id = f.tunnel_id AND o.profile = $2
+		JOIN tunnel n ON n.profile = $1 AND n.name = o.name
+		ORDER BY f.id`, name, from)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *configService) deleteProfile(name string) error {
+	active, err := s.getActiveProfile()
+	if err != nil {
+		return err
+	}
+	if name == active {
+		return errors.New("cannot delete the active profile")
+	}
+	exists, err := s.profileExists(name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("profile %q does not exist", name)
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("DELETE FROM profile_config WHERE profile = $1", name)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	_, err = tx.Exec("DELETE FROM tunnel_forward WHERE tunnel_id IN (SELECT id FROM tunnel WHERE profile = $1)", name)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	_, err = tx.Exec("DELETE FROM tunnel WHERE profile = $1", name)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	_, err = tx.Exec("DELETE FROM profile WHERE name = $1", name)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// seedProfile is the profile the migrations create and seed.
+const seedProfile = "staging"
+
+func (s *configService) profileExists(name string) (bool, error) {
+	var count int
+	err := s.db.Get(&count, "SELECT COUNT(*) FROM profile WHERE name = $1", name)
+	return count > 0, err
+}
+
+// importProfiles creates or overwrites profiles. Keys missing from the
+// import keep their value or are copied from the seeded profile.
+func (s *configService) importProfiles(profiles []Profile) error {
+	active, err := s.getActiveProfile()
+	if err != nil {
+		return err
+	}
+	keys, err := s.getProfileConfigs(active)
+	if err != nil {
+		return err
+	}
+	base := seedProfile
+	exists, err := s.profileExists(base)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		base = active
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, p := range profiles {
+		if len(p.Name) == 0 {
+			tx.Rollback()
+			return errors.New("profile name is required")
+		}
+		_, err = tx.Exec("INSERT OR IGNORE INTO profile (name) VALUES ($1)", p.Name)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		values := make(map[string]string)
+		for _, c := range p.Configs {
+			values[c.Key] = c.Value
+		}
+		for _, k := range keys {
+			value, ok := values[k.Key]
+			if !ok {
+				_, err = tx.Exec(`INSERT OR IGNORE INTO profile_config (profile, "key", "value")
+					SELECT $1, "key", "value" FROM profile_config WHERE profile = $2 AND "key" = $3`, p.Name, base, k.Key)
+				if err != nil {
+					tx.Rollback()
+					return err
+				}
+				continue
+			}
+			_, err = tx.Exec(`INSERT INTO profile_config (profile, "key", "value") VALUES ($1, $2, $3)
+				ON CONFLICT (profile, "key") DO UPDATE SET "value" = excluded."value"`, p.Name, k.Key, value)
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+func (a *App) GetProfiles() ([]Profile, error) {
+	profiles, err := a.configService.getProfiles()
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return nil, err
+	}
+	for i := range profiles {
+		profiles[i].Configs = redactSecrets(profiles[i].Configs)
+	}
+	return profiles, nil
+}
+
+func (a *App) GetActiveProfile() (string, error) {
+	profile, err := a.configService.getActiveProfile()
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return "", err
+	}
+	return profile, nil
+}
+
+func (a *App) SwitchProfile(name string) error {
+	err := a.configService.switchProfile(name)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	a.linkManager.closeAll()
+	a.tunnelManager.stopAll()
+	log.Printf("switched to profile %s", name)
+	runtime.EventsEmit(a.ctx, "profile", name)
+	return nil
+}
+
+func (a *App) CreateProfile(name string, from string) error {
+	err := a.configService.createProfile(name, from)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	return nil
+}
+
+func (a *App) DeleteProfile(name string) error {
+	err := a.configService.deleteProfile(name)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	return nil
+}
+
+func (a *App) ExportProfiles() (string, error) {
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{DefaultFilename: "profiles.json"})
+	if err != nil || len(path) == 0 {
+		return "", err
+	}
+	profiles, err := a.configService.getProfiles()
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return "", err
+	}
+	// secrets stay in the vault and an import keeps the stored ones
+	for i := range profiles {
+		profiles[i].Configs = redactSecrets(profiles[i].Configs)
+		profiles[i].Tunnels, err = a.tunnelManager.getTunnels(profiles[i].Name)
+		if err != nil {
+			log.Error().Err(err).Msg("")
+			return "", err
+		}
+	}
+	data, err := json.MarshalIndent(profiles, "", "  ")
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return "", err
+	}
+	err = os.WriteFile(path, data, 0600)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return "", err
+	}
+	return path, nil
+}
+
+func (a *App) ImportProfiles() (int, error) {
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{})
+	if err != nil || len(path) == 0 {
+		return 0, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return 0, err
+	}
+	profiles := []Profile{}
+	err = json.Unmarshal(data, &profiles)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return 0, err
+	}
+	for i := range profiles {
+		profiles[i].Configs, err = a.secretService.sealConfigs(profiles[i].Configs)
+		if err != nil {
+			log.Error().Err(err).Msg("")
+			return 0, err
+		}
+	}
+	err = a.configService.importProfiles(profiles)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return 0, err
+	}
+	for _, p := range profiles {
+		for _, t := range p.Tunnels {
+			err = a.tunnelManager.saveTunnel(p.Name, t)
+			if err != nil {
+				log.Error().Err(err).Msg("")
+				return 0, err
+			}
+		}
+	}
+	active, err := a.configService.getActiveProfile()
+	if err == nil {
+		err = a.configService.switchProfile(active)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return 0, err
+	}
+	return len(profiles), nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestProfiles(t *testing.T) {
+	s := &configService{db: newTestDB(t)}
+	if err := s.createProfile("prod", "missing"); err == nil {
+		t.Error("createProfile() from a missing profile should fail")
+	}
+	if err := s.deleteProfile("missing"); err == nil {
+		t.Error("deleteProfile() of a missing profile should fail")
+	}
+	if err := s.createProfile("prod", seedProfile); err != nil {
+		t.Fatal(err)
+	}
+	seeded, _ := s.getProfileConfigs(seedProfile)
+	created, _ := s.getProfileConfigs("prod")
+	if len(created) == 0 || len(created) != len(seeded) {
+		t.Errorf("createProfile() copied %d settings, want %d", len(created), len(seeded))
+	}
+	if err := s.deleteProfile("prod"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.deleteProfile(seedProfile); err == nil {
+		t.Error("deleteProfile() of the active profile should fail")
+	}
+
+	err := s.importProfiles([]Profile{{Name: "uat", Configs: []Config{{Key: "CORTEX_TIMEOUT", Value: "45"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := make(map[string]string)
+	imported, _ := s.getProfileConfigs("uat")
+	for _, c := range imported {
+		values[c.Key] = c.Value
+	}
+	if len(imported) != len(seeded) || values["CORTEX_TIMEOUT"] != "45" || values["SAF_MAX_ATTEMPTS"] != "5" {
+		t.Errorf("importProfiles() = %v", values)
+	}
+}
@@ -0,0 +1,500 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+type ReconFormat string
+
+const (
+	CSV_FORMAT         ReconFormat = "CSV"
+	FIXED_WIDTH_FORMAT ReconFormat = "FIXED_WIDTH"
+)
+
+type ReconStatus string
+
+const (
+	MATCHED         ReconStatus = "MATCHED"
+	MISSING         ReconStatus = "MISSING"
+	UNEXPECTED      ReconStatus = "UNEXPECTED"
+	AMOUNT_MISMATCH ReconStatus = "AMOUNT_MISMATCH"
+)
+
+// ReconMapping is the layout of a settlement file. Columns are 1-based, "3"
+// for CSV or "10-21" for fixed width, and empty ones are not matched on.
+type ReconMapping struct {
+	Switch      AtmSwitch   `db:"switch" json:"switch"`
+	Format      ReconFormat `db:"format" json:"format"`
+	Delimiter   string      `db:"delimiter" json:"delimiter"`
+	HeaderLines int         `db:"header_lines" json:"headerLines"`
+	FooterLines int         `db:"footer_lines" json:"footerLines"`
+	Rrn         string      `db:"rrn" json:"rrn"`
+	Stan        string      `db:"stan" json:"stan"`
+	TerminalId  string      `db:"terminal_id" json:"terminalId"`
+	Amount      string      `db:"amount" json:"amount"`
+	MinorUnits  bool        `db:"minor_units" json:"minorUnits"`
+}
+
+type ReconEntry struct {
+	Status        ReconStatus `json:"status"`
+	Line          int         `json:"line,omitempty"`
+	MessageId     int         `json:"messageId,omitempty"`
+	Rrn           string      `json:"rrn"`
+	Stan          string      `json:"stan"`
+	TerminalId    string      `json:"terminalId"`
+	FileAmount    float64     `json:"fileAmount"`
+	MessageAmount float64     `json:"messageAmount"`
+	Currency      Currency    `json:"currency,omitempty"`
+}
+
+type ReconReport struct {
+	Switch     AtmSwitch    `json:"switch"`
+	File       string       `json:"file"`
+	From       string       `json:"from"`
+	To         string       `json:"to"`
+	Matched    int          `json:"matched"`
+	Missing    int          `json:"missing"`
+	Unexpected int          `json:"unexpected"`
+	Mismatched int          `json:"mismatched"`
+	Entries    []ReconEntry `json:"entries"`
+}
+
+type settlementRecord struct {
+	line       int
+	rrn        string
+	stan       string
+	terminalId string
+	amount     float64
+	minorUnits bool
+}
+
+type reconService struct {
+	db *sqlx.DB
+}
+
+func (s *reconService) getMapping(atmSwitch AtmSwitch) (ReconMapping, error) {
+	mapping := ReconMapping{}
+	err := s.db.Get(&mapping, "SELECT * FROM recon_mapping WHERE switch=$1", atmSwitch)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ReconMapping{Switch: atmSwitch, Format: CSV_FORMAT, Delimiter: ","}, nil
+	}
+	return mapping, err
+}
+
+func (s *reconService) saveMapping(mapping ReconMapping) error {
+	err := validateMapping(mapping)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.NamedExec(`INSERT OR REPLACE INTO recon_mapping (
+		switch, format, delimiter, header_lines, footer_lines, rrn, stan, terminal_id, amount, minor_units
+	  ) VALUES (
+		:switch, :format, :delimiter, :header_lines, :footer_lines, :rrn, :stan, :terminal_id, :amount, :minor_units
+	  )`, mapping)
+	return err
+}
+
+func validateMapping(mapping ReconMapping) error {
+	if mapping.Format != CSV_FORMAT && mapping.Format != FIXED_WIDTH_FORMAT {
+		return fmt.Errorf("invalid format %q, expected CSV or FIXED_WIDTH", mapping.Format)
+	}
+	if mapping.Format == CSV_FORMAT && len([]rune(mapping.Delimiter)) != 1 {
+		return errors.New("delimiter must be a single character")
+	}
+	if mapping.HeaderLines < 0 || mapping.FooterLines < 0 {
+		return errors.New("header and footer lines cannot be negative")
+	}
+	if len(mapping.Amount) == 0 {
+		return errors.New("amount column is required")
+	}
+	if len(mapping.Rrn) == 0 && len(mapping.Stan) == 0 {
+		return errors.New("RRN or STAN column is required")
+	}
+	for _, column := range []string{mapping.Rrn, mapping.Stan, mapping.TerminalId, mapping.Amount} {
+		if len(column) == 0 {
+			continue
+		}
+		if _, _, err := parseColumn(mapping.Format, column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseColumn returns the 0-based start and end of a column.
+func parseColumn(format ReconFormat, column string) (int, int, error) {
+	if format == CSV_FORMAT {
+		n, err := strconv.Atoi(column)
+		if err != nil || n < 1 {
+			return 0, 0, fmt.Errorf("invalid CSV column %q, expected a column number from 1", column)
+		}
+		return n - 1, n, nil
+	}
+	start, end, ok := strings.Cut(column, "-")
+	from, err := strconv.Atoi(start)
+	if err != nil || !ok || from < 1 {
+		return 0, 0, fmt.Errorf("invalid fixed width column %q, expected a position range such as 10-21", column)
+	}
+	to, err := strconv.Atoi(end)
+	if err != nil || to < from {
+		return 0, 0, fmt.Errorf("invalid fixed width column %q, expected a position range such as 10-21", column)
+	}
+	return from - 1, to, nil
+}
+
+func readSettlement(r io.Reader, mapping ReconMapping) ([]settlementRecord, error) {
+	var rows [][]string
+	var lines []string
+	if mapping.Format == CSV_FORMAT {
+		reader := csv.NewReader(r)
+		reader.Comma = []rune(mapping.Delimiter)[0]
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		var err error
+		rows, err = reader.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			lines = append(lines, strings.TrimRight(scanner.Text(), "\r"))
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+	count := len(rows) + len(lines)
+	if mapping.HeaderLines+mapping.FooterLines > count {
+		return nil, errors.New("file has fewer lines than the header and footer")
+	}
+
+	value := func(i int, column string) (string, error) {
+		if len(column) == 0 {
+			return "", nil
+		}
+		start, end, err := parseColumn(mapping.Format, column)
+		if err != nil {
+			return "", err
+		}
+		if mapping.Format == CSV_FORMAT {
+			if start >= len(rows[i]) {
+				return "", fmt.Errorf("line %d has no column %s", i+1, column)
+			}
+			return strings.TrimSpace(rows[i][start]), nil
+		}
+		if end > len(lines[i]) {
+			return "", fmt.Errorf("line %d is shorter than column %s", i+1, column)
+		}
+		return strings.TrimSpace(lines[i][start:end]), nil
+	}
+
+	records := []settlementRecord{}
+	for i := mapping.HeaderLines; i < count-mapping.FooterLines; i++ {
+		if mapping.Format == FIXED_WIDTH_FORMAT && len(strings.TrimSpace(lines[i])) == 0 {
+			continue
+		}
+		record := settlementRecord{line: i + 1}
+		var amount string
+		var err error
+		for _, f := range []struct {
+			column string
+			value  *string
+		}{
+			{mapping.Rrn, &record.rrn},
+			{mapping.Stan, &record.stan},
+			{mapping.TerminalId, &record.terminalId},
+			{mapping.Amount, &amount},
+		} {
+			*f.value, err = value(i, f.column)
+			if err != nil {
+				return nil, err
+			}
+		}
+		record.amount, err = parseSettlementAmount(amount)
+		record.minorUnits = mapping.MinorUnits
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func parseSettlementAmount(amount string) (float64, error) {
+	amount = strings.ReplaceAll(amount, ",", "")
+	value, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", amount)
+	}
+	return value, nil
+}
+
+// amountIn converts the minor units some files list.
+func (r settlementRecord) amountIn(currency Currency) float64 {
+	if r.minorUnits {
+		return fromMinorUnits(int64(math.Round(r.amount)), currency)
+	}
+	return r.amount
+}
+
+// getReconMessages includes both dates and the reversals of the messages.
+func (s *reconService) getReconMessages(atmSwitch AtmSwitch, from string, to string) ([]Message, error) {
+	start, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from date %q", from)
+	}
+	end, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return nil, fmt.Errorf("invalid to date %q", to)
+	}
+	messages := []Message{}
+	err = s.db.Select(&messages, `SELECT * FROM atm_message
+		WHERE switch=$1 AND local_transaction_date_time BETWEEN $2 AND $3
+		ORDER BY id`, atmSwitch, start.Format("060102")+"000000", end.Format("060102")+"235959")
+	return messages, err
+}
+
+// settledMessages lists the approved requests the switch settles, at the
+// amount left after a partial reversal.
+func settledMessages(messages []Message) []Message {
+	reversed := make(map[int]float64)
+	for _, m := range messages {
+		if m.ParentId > 0 && isReversalMti(m.Mti) && approved(m.ResponseCode) {
+			reversed[m.ParentId] = m.ReplacementAmount
+		}
+	}
+	settled := []Message{}
+	for _, m := range messages {
+		if m.ParentId > 0 || isReversalMti(m.Mti) || !approved(m.ResponseCode) || m.TransactionAmount == 0 {
+			continue
+		}
+		if isHold(m.Transaction) || (isDeposit(m.Transaction) && m.AuthorizationId == 0) {
+			continue
+		}
+		if replacement, ok := reversed[m.Id]; ok {
+			if replacement == 0 {
+				continue
+			}
+			m.TransactionAmount = replacement
+		}
+		settled = append(settled, m)
+	}
+	return settled
+}
+
+// settlementCurrency is empty when the messages use several currencies.
+func settlementCurrency(messages []Message) Currency {
+	var currency Currency
+	for i, m := range messages {
+		if i > 0 && m.CurrencyCode != currency {
+			return ""
+		}
+		currency = m.CurrencyCode
+	}
+	return currency
+}
+
+func sameAmount(a float64, b float64, currency Currency) bool {
+	return minorUnits(a, currency) == minorUnits(b, currency)
+}
+
+func (r settlementRecord) matches(message Message) bool {
+	if len(r.rrn) > 0 && r.rrn != message.Rrn {
+		return false
+	}
+	if len(r.stan) > 0 && r.stan != message.TraceNumber {
+		return false
+	}
+	if len(r.terminalId) > 0 && r.terminalId != message.TerminalID {
+		return false
+	}
+	return len(r.rrn) > 0 || len(r.stan) > 0
+}
+
+// reconcile pairs records and messages by key, preferring equal amounts.
+func reconcile(records []settlementRecord, messages []Message) []ReconEntry {
+	messages = settledMessages(messages)
+	currency := settlementCurrency(messages)
+	used := make([]bool, len(messages))
+	entries := []ReconEntry{}
+	for _, record := range records {
+		entry := ReconEntry{
+			Status:     UNEXPECTED,
+			Line:       record.line,
+			Rrn:        record.rrn,
+			Stan:       record.stan,
+			TerminalId: record.terminalId,
+			FileAmount: record.amountIn(currency),
+			Currency:   currency,
+		}
+		match := -1
+		for i, message := range messages {
+			if used[i] || !record.matches(message) {
+				continue
+			}
+			if match < 0 {
+				match = i
+			}
+			if sameAmount(record.amountIn(message.CurrencyCode), message.TransactionAmount, message.CurrencyCode) {
+				match = i
+				break
+			}
+		}
+		if match >= 0 {
+			used[match] = true
+			message := messages[match]
+			entry.MessageId = message.Id
+			entry.Rrn = message.Rrn
+			entry.Stan = message.TraceNumber
+			entry.TerminalId = message.TerminalID
+			entry.MessageAmount = message.TransactionAmount
+			entry.FileAmount = record.amountIn(message.CurrencyCode)
+			entry.Currency = message.CurrencyCode
+			entry.Status = MATCHED
+			if !sameAmount(entry.FileAmount, message.TransactionAmount, message.CurrencyCode) {
+				entry.Status = AMOUNT_MISMATCH
+			}
+		}
+		entries = append(entries, entry)
+	}
+	for i, message := range messages {
+		if used[i] {
+			continue
+		}
+		entries = append(entries, ReconEntry{
+			Status:        MISSING,
+			MessageId:     message.Id,
+			Rrn:           message.Rrn,
+			Stan:          message.TraceNumber,
+			TerminalId:    message.TerminalID,
+			MessageAmount: message.TransactionAmount,
+			Currency:      message.CurrencyCode,
+		})
+	}
+	return entries
+}
+
+func (s *reconService) reconcileFile(path string, atmSwitch AtmSwitch, from string, to string) (ReconReport, error) {
+	mapping, err := s.getMapping(atmSwitch)
+	if err != nil {
+		return ReconReport{}, err
+	}
+	err = validateMapping(mapping)
+	if err != nil {
+		return ReconReport{}, fmt.Errorf("%s settlement mapping: %w", atmSwitch, err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return ReconReport{}, err
+	}
+	defer file.Close()
+	records, err := readSettlement(file, mapping)
+	if err != nil {
+		return ReconReport{}, err
+	}
+	messages, err := s.getReconMessages(atmSwitch, from, to)
+	if err != nil {
+		return ReconReport{}, err
+	}
+	report := ReconReport{Switch: atmSwitch, File: path, From: from, To: to, Entries: reconcile(records, messages)}
+	for _, entry := range report.Entries {
+		switch entry.Status {
+		case MATCHED:
+			report.Matched++
+		case MISSING:
+			report.Missing++
+		case UNEXPECTED:
+			report.Unexpected++
+		case AMOUNT_MISMATCH:
+			report.Mismatched++
+		}
+	}
+	return report, nil
+}
+
+func writeReconReport(w io.Writer, report ReconReport) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"status", "line", "message_id", "rrn", "stan", "terminal_id", "file_amount", "message_amount"})
+	for _, e := range report.Entries {
+		line, messageId := "", ""
+		if e.Line > 0 {
+			line = strconv.Itoa(e.Line)
+		}
+		if e.MessageId > 0 {
+			messageId = strconv.Itoa(e.MessageId)
+		}
+		writer.Write([]string{
+			string(e.Status), line, messageId, e.Rrn, e.Stan, e.TerminalId,
+			formatAmount(e.FileAmount, e.Currency), formatAmount(e.MessageAmount, e.Currency),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (a *App) GetReconMapping(atmSwitch AtmSwitch) (ReconMapping, error) {
+	mapping, err := a.reconService.getMapping(atmSwitch)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return ReconMapping{}, err
+	}
+	return mapping, nil
+}
+
+func (a *App) SaveReconMapping(mapping ReconMapping) error {
+	err := a.reconService.saveMapping(mapping)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	return nil
+}
+
+// ImportSettlement takes from and to as 2006-01-02.
+func (a *App) ImportSettlement(atmSwitch AtmSwitch, from string, to string) (ReconReport, error) {
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{})
+	if err != nil || len(path) == 0 {
+		return ReconReport{}, err
+	}
+	report, err := a.reconService.reconcileFile(path, atmSwitch, from, to)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return ReconReport{}, err
+	}
+	return report, nil
+}
+
+func (a *App) ExportReconReport(report ReconReport) (string, error) {
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{DefaultFilename: "reconciliation.csv"})
+	if err != nil || len(path) == 0 {
+		return "", err
+	}
+	var b bytes.Buffer
+	err = writeReconReport(&b, report)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return "", err
+	}
+	err = os.WriteFile(path, b.Bytes(), 0600)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return "", err
+	}
+	return path, nil
+}
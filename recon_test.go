@@ -0,0 +1,38 @@
+package main
+
+import "testing"
+
+func TestReconcile(t *testing.T) {
+	messages := []Message{
+		{Id: 1, Transaction: WITHDRAW, Mti: "0200", Rrn: "000000000001", TransactionAmount: 1000, CurrencyCode: PHP, ResponseCode: "00"},
+		{Id: 2, Transaction: WITHDRAW, Mti: "0200", Rrn: "000000000002", TransactionAmount: 1000, CurrencyCode: PHP, ResponseCode: "00"},
+		{Id: 3, ParentId: 2, Transaction: WITHDRAW, Mti: "0420", Rrn: "000000000002", TransactionAmount: 1000, ReplacementAmount: 400, CurrencyCode: PHP, ResponseCode: "00"},
+		{Id: 4, Transaction: WITHDRAW, Mti: "0200", Rrn: "000000000004", TransactionAmount: 1000, CurrencyCode: PHP, ResponseCode: "00"},
+		{Id: 5, ParentId: 4, Transaction: WITHDRAW, Mti: "0420", Rrn: "000000000004", TransactionAmount: 1000, CurrencyCode: PHP, ResponseCode: "00"},
+		{Id: 6, Transaction: WITHDRAW, Mti: "0200", Rrn: "000000000006", TransactionAmount: 1000, CurrencyCode: PHP, ResponseCode: "51"},
+		{Id: 7, Transaction: BAL_INQ, Mti: "0200", Rrn: "000000000007", CurrencyCode: PHP, ResponseCode: "00"},
+		{Id: 8, Transaction: WITHDRAW, Mti: "0200", Rrn: "000000000008", TransactionAmount: 500, CurrencyCode: PHP, ResponseCode: "00"},
+	}
+	records := []settlementRecord{
+		{line: 1, rrn: "000000000001", amount: 100000, minorUnits: true},
+		{line: 2, rrn: "000000000002", amount: 40000, minorUnits: true},
+		{line: 3, rrn: "000000000006", amount: 100000, minorUnits: true},
+		{line: 4, rrn: "000000000008", amount: 49999, minorUnits: true},
+	}
+	want := []ReconStatus{MATCHED, MATCHED, UNEXPECTED, AMOUNT_MISMATCH}
+	entries := reconcile(records, messages)
+	if len(entries) != len(want) {
+		t.Fatalf("reconcile() = %+v, want %v", entries, want)
+	}
+	for i, entry := range entries {
+		if entry.Status != want[i] {
+			t.Errorf("reconcile() line %d = %s, want %s", records[i].line, entry.Status, want[i])
+		}
+	}
+
+	yen := []Message{{Id: 1, Transaction: WITHDRAW, Mti: "0200", Rrn: "000000000001", TransactionAmount: 13000, CurrencyCode: "392", ResponseCode: "00"}}
+	entries = reconcile([]settlementRecord{{line: 1, rrn: "000000000001", amount: 13000, minorUnits: true}}, yen)
+	if len(entries) != 1 || entries[0].Status != MATCHED {
+		t.Errorf("reconcile() in JPY = %+v, want MATCHED", entries)
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ReversalReason is the message reason code of a reversal.
+type ReversalReason string
+
+const (
+	CUSTOMER_CANCELLATION ReversalReason = "17"
+	UNABLE_TO_DELIVER     ReversalReason = "21"
+	SUSPECTED_MALFUNCTION ReversalReason = "22"
+	PARTIAL_DISPENSE      ReversalReason = "32"
+	RESPONSE_TIMEOUT      ReversalReason = "68"
+)
+
+var reversalReasons = []ReversalReason{
+	CUSTOMER_CANCELLATION,
+	UNABLE_TO_DELIVER,
+	SUSPECTED_MALFUNCTION,
+	PARTIAL_DISPENSE,
+	RESPONSE_TIMEOUT,
+}
+
+// ReversalStatus is the state of the reversal of an original message.
+type ReversalStatus string
+
+const (
+	REVERSAL_PENDING  ReversalStatus = "PENDING"
+	REVERSAL_APPROVED ReversalStatus = "REVERSED"
+	REVERSAL_DECLINED ReversalStatus = "DECLINED"
+	REVERSAL_FAILED   ReversalStatus = "FAILED"
+)
+
+// ReversalOptions with a dispensed amount make a partial reversal.
+type ReversalOptions struct {
+	DispensedAmount float64        `json:"dispensedAmount"`
+	Reason          ReversalReason `json:"reason"`
+}
+
+func applyReversalOptions(message *Message, options ReversalOptions) error {
+	if options.DispensedAmount < 0 {
+		return fmt.Errorf("dispensed amount cannot be negative")
+	}
+	if options.DispensedAmount > 0 && minorUnits(options.DispensedAmount, message.CurrencyCode) >= minorUnits(message.TransactionAmount, message.CurrencyCode) {
+		return fmt.Errorf("dispensed amount must be less than the transaction amount %.2f", message.TransactionAmount)
+	}
+	reason := options.Reason
+	if len(reason) == 0 {
+		reason = RESPONSE_TIMEOUT
+		if options.DispensedAmount > 0 {
+			reason = PARTIAL_DISPENSE
+		}
+	}
+	known := false
+	for _, r := range reversalReasons {
+		known = known || r == reason
+	}
+	if !known {
+		return fmt.Errorf("reversal reason %q not supported", reason)
+	}
+	message.ReplacementAmount = options.DispensedAmount
+	message.ReversalReason = reason
+	return nil
+}
+
+// serializeReplacementAmounts formats field 95 of Narada and Postbridge.
+func serializeReplacementAmounts(message Message) string {
+	amount := padLeftWithZeros(moveDecimalRight(message.ReplacementAmount, message.CurrencyCode), 12)
+	fee := "D" + padLeftWithZeros(moveDecimalRight(message.TransactionFee, message.CurrencyCode), 8)
+	return amount + amount + fee + fee
+}
+
+// MessageLifecycle lists the reversals of a message, oldest first.
+type MessageLifecycle struct {
+	Original     Message        `json:"original"`
+	Reversals    []Message      `json:"reversals"`
+	Status       ReversalStatus `json:"status"`
+	ResponseCode string         `json:"responseCode"`
+}
+
+// approved accepts the action code 000 of the 1993 version.
+func approved(responseCode string) bool {
+	return responseCode == "00" || responseCode == "000"
+}
+
+func isReversalMti(mti string) bool {
+	return len(mti) == 4 && mti[1] == '4'
+}
+
+// newReversal copies the original into a reversal linked to it.
+func newReversal(original Message, options ReversalOptions) (Message, error) {
+	if original.ParentId > 0 || isReversalMti(original.Mti) {
+		return Message{}, fmt.Errorf("message %d is a reversal", original.Id)
+	}
+	reversal := original
+	err := applyReversalOptions(&reversal, options)
+	if err != nil {
+		return Message{}, err
+	}
+	reversal.ParentId = original.Id
+	reversal.ResponseCode = ""
+	reversal.ReversalStatus = ""
+	reversal.ReversalResponseCode = ""
+	return reversal, nil
+}
+
+// reversalOutcome fails reversals that were not answered so they can be
+// retried.
+func reversalOutcome(response AtmResponse, err error) (ReversalStatus, string) {
+	if err != nil {
+		return REVERSAL_FAILED, ""
+	}
+	if approved(response.ResponseCode) {
+		return REVERSAL_APPROVED, response.ResponseCode
+	}
+	return REVERSAL_DECLINED, response.ResponseCode
+}
+
+// claimReversal marks the original as being reversed unless it already is.
+func (s *messageService) claimReversal(original Message) error {
+	result, err := s.db.Exec(`UPDATE atm_message SET reversal_status=$1, reversal_response_code=''
+		WHERE id=$2 AND reversal_status NOT IN ($1, $3)`, REVERSAL_PENDING, original.Id, REVERSAL_APPROVED)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+	current, err := s.getMessage(original.Id)
+	if err != nil {
+		return err
+	}
+	if current.ReversalStatus == REVERSAL_APPROVED {
+		return fmt.Errorf("message %d is already reversed", original.Id)
+	}
+	return fmt.Errorf("a reversal of message %d is still pending", original.Id)
+}
+
+func (s *messageService) settleReversal(id int, status ReversalStatus, responseCode string) error {
+	_, err := s.db.Exec("UPDATE atm_message SET reversal_status=$1, reversal_response_code=$2 WHERE id=$3", status, responseCode, id)
+	return err
+}
+
+// settleReversalOf records the outcome of a reversal on its original.
+func (s *messageService) settleReversalOf(reversalId int, status ReversalStatus, responseCode string) error {
+	_, err := s.db.Exec(`UPDATE atm_message SET reversal_status=$1, reversal_response_code=$2
+		WHERE id=(SELECT parent_id FROM atm_message WHERE id=$3)`, status, responseCode, reversalId)
+	return err
+}
+
+// recoverReversals fails the reversals a previous run left pending, except
+// queued advices.
+func (s *messageService) recoverReversals() error {
+	_, err := s.db.Exec(`UPDATE atm_message SET reversal_status=$1 WHERE reversal_status=$2 AND id NOT IN (
+		SELECT m.parent_id FROM saf_item i JOIN atm_message m ON m.id = i.message_id WHERE i.status=$3)`,
+		REVERSAL_FAILED, REVERSAL_PENDING, SAF_PENDING)
+	return err
+}
+
+func (s *messageService) getLifecycle(id int) (MessageLifecycle, error) {
+	original, err := s.getMessage(id)
+	if err != nil {
+		return MessageLifecycle{}, err
+	}
+	if original.ParentId > 0 {
+		original, err = s.getMessage(original.ParentId)
+		if err != nil {
+			return MessageLifecycle{}, err
+		}
+	}
+	reversals := []Message{}
+	err = s.db.Select(&reversals, "SELECT * FROM atm_message WHERE parent_id=$1 ORDER BY id", original.Id)
+	if err != nil {
+		return MessageLifecycle{}, err
+	}
+	return MessageLifecycle{
+		Original:     original,
+		Reversals:    reversals,
+		Status:       original.ReversalStatus,
+		ResponseCode: original.ReversalResponseCode,
+	}, nil
+}
+
+func (a *App) GetMessageLifecycle(id int) (MessageLifecycle, error) {
+	lifecycle, err := a.messageService.getLifecycle(id)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return MessageLifecycle{}, err
+	}
+	return lifecycle, nil
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/moov-io/iso8583"
+)
+
+func TestApplyReversalOptions(t *testing.T) {
+	tests := []struct {
+		options     ReversalOptions
+		replacement float64
+		reason      ReversalReason
+		wantErr     bool
+	}{
+		{options: ReversalOptions{}, reason: RESPONSE_TIMEOUT},
+		{options: ReversalOptions{DispensedAmount: 400}, replacement: 400, reason: PARTIAL_DISPENSE},
+		{options: ReversalOptions{Reason: CUSTOMER_CANCELLATION}, reason: CUSTOMER_CANCELLATION},
+		{options: ReversalOptions{Reason: "99"}, wantErr: true},
+		{options: ReversalOptions{DispensedAmount: -1}, wantErr: true},
+		{options: ReversalOptions{DispensedAmount: 1000}, wantErr: true},
+	}
+	for _, tt := range tests {
+		message := Message{TransactionAmount: 1000, CurrencyCode: PHP}
+		err := applyReversalOptions(&message, tt.options)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("applyReversalOptions(%+v) error = %v, wantErr %v", tt.options, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (message.ReplacementAmount != tt.replacement || message.ReversalReason != tt.reason) {
+			t.Errorf("applyReversalOptions(%+v) = %v %s, want %v %s", tt.options, message.ReplacementAmount, message.ReversalReason, tt.replacement, tt.reason)
+		}
+	}
+}
+
+func TestSerializeReplacementAmounts(t *testing.T) {
+	message := Message{ReplacementAmount: 400, TransactionFee: 15, CurrencyCode: PHP}
+	want := "000000040000000000040000D00001500D00001500"
+	if got := serializeReplacementAmounts(message); got != want {
+		t.Errorf("serializeReplacementAmounts() = %s, want %s", got, want)
+	}
+}
+
+func TestCortexPartialReversal(t *testing.T) {
+	message := Message{
+		Switch:                   CORTEX,
+		Transaction:              WITHDRAW,
+		Device:                   ATM,
+		Mti:                      "1200",
+		ProcessCode:              "011000",
+		TransactionAmount:        1000,
+		CurrencyCode:             PHP,
+		TransmissionDateTime:     "1019120000",
+		TraceNumber:              "123456",
+		LocalTransactionDateTime: "261019120000",
+		AcquiringInstitutionCode: "1234",
+		Rrn:                      "000000123456",
+	}
+	err := applyReversalOptions(&message, ReversalOptions{DispensedAmount: 400})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cortex.build(&message, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := cortex.pack(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	packed := iso8583.NewMessage(fisGlobalSpec)
+	err = packed.Unpack(b[len(header):])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, want := range map[int]string{0: "1400", 4: "000000040000", 30: "000000100000", 95: ""} {
+		if got, _ := packed.GetString(id); got != want {
+			t.Errorf("field %d = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestReversalStatus(t *testing.T) {
+	s := &messageService{db: newTestDB(t)}
+	original := newTestMessage()
+	id, err := s.saveMessage(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	original.Id = id
+	status := func() ReversalStatus {
+		m, err := s.getMessage(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m.ReversalStatus
+	}
+
+	if err := s.claimReversal(original); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.claimReversal(original); err == nil {
+		t.Error("claimReversal() of a pending reversal should fail")
+	}
+	if err := s.settleReversal(id, REVERSAL_FAILED, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.claimReversal(original); err != nil {
+		t.Errorf("claimReversal() after a failed reversal = %v", err)
+	}
+
+	reversal, err := newReversal(original, ReversalOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reversal.Mti = "1420"
+	reversalId, err := s.saveMessage(reversal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.settleReversalOf(reversalId, REVERSAL_APPROVED, "400"); err != nil {
+		t.Fatal(err)
+	}
+	if got := status(); got != REVERSAL_APPROVED {
+		t.Errorf("status after settleReversalOf() = %s, want %s", got, REVERSAL_APPROVED)
+	}
+	if err := s.claimReversal(original); err == nil || !strings.Contains(err.Error(), "already reversed") {
+		t.Errorf("claimReversal() of a reversed message = %v", err)
+	}
+	if _, err := newReversal(reversal, ReversalOptions{}); err == nil {
+		t.Error("newReversal() of a reversal should fail")
+	}
+}
+
+func TestRecoverReversals(t *testing.T) {
+	s := &messageService{db: newTestDB(t)}
+	var ids []int
+	for _, stan := range []string{"000001", "000002"} {
+		message := newTestMessage()
+		message.TraceNumber = stan
+		id, err := s.saveMessage(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		message.Id = id
+		if err := s.claimReversal(message); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	// the reversal of the second message is still queued as an advice
+	advice := newTestMessage()
+	advice.ParentId, advice.Mti = ids[1], "1421"
+	adviceId, err := s.saveMessage(advice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.db.Exec(`INSERT INTO saf_item (message_id, switch, mti, status, created_at) VALUES ($1, $2, $3, $4, '')`,
+		adviceId, advice.Switch, advice.Mti, SAF_PENDING)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.recoverReversals(); err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []ReversalStatus{REVERSAL_FAILED, REVERSAL_PENDING} {
+		m, _ := s.getMessage(ids[i])
+		if m.ReversalStatus != want {
+			t.Errorf("message %d after recoverReversals() = %s, want %s", ids[i], m.ReversalStatus, want)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+type SafStatus string
+
+const (
+	SAF_PENDING SafStatus = "PENDING"
+	SAF_FAILED  SafStatus = "FAILED"
+)
+
+const defaultSafInterval = 30 * time.Second
+
+// SafItem is an advice waiting for the host to acknowledge it.
+type SafItem struct {
+	Id            int       `db:"id" json:"id"`
+	MessageId     int       `db:"message_id" json:"messageId"`
+	Switch        AtmSwitch `db:"switch" json:"switch"`
+	Mti           string    `db:"mti" json:"mti"`
+	Status        SafStatus `db:"status" json:"status"`
+	Attempts      int       `db:"attempts" json:"attempts"`
+	LastError     string    `db:"last_error" json:"lastError"`
+	CreatedAt     string    `db:"created_at" json:"createdAt"`
+	LastAttemptAt string    `db:"last_attempt_at" json:"lastAttemptAt"`
+	Transaction   string    `db:"transaction" json:"transaction"`
+	TraceNumber   string    `db:"trace_number" json:"traceNumber"`
+	Amount        float64   `db:"transaction_amount" json:"amount"`
+}
+
+type safQueue struct {
+	ctx      context.Context
+	db       *sqlx.DB
+	messages *messageService
+	send     func(target AtmSwitch, sw atmSwitch, b []byte) ([]byte, error)
+	mu       sync.Mutex
+	cancel   context.CancelFunc
+	done     chan struct{}
+	wake     chan struct{}
+}
+
+func newSafQueue(ctx context.Context, db *sqlx.DB, messages *messageService, send func(AtmSwitch, atmSwitch, []byte) ([]byte, error)) *safQueue {
+	return &safQueue{
+		ctx:      ctx,
+		db:       db,
+		messages: messages,
+		send:     send,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+func (q *safQueue) getItems() ([]SafItem, error) {
+	items := []SafItem{}
+	err := q.db.Select(&items, `SELECT s.*, m."transaction", m.trace_number, m.transaction_amount
+		FROM saf_item s JOIN atm_message m ON m.id = s.message_id ORDER BY s.id`)
+	return items, err
+}
+
+func (q *safQueue) getItem(id int) (SafItem, error) {
+	item := SafItem{}
+	err := q.db.Get(&item, `SELECT s.*, m."transaction", m.trace_number, m.transaction_amount
+		FROM saf_item s JOIN atm_message m ON m.id = s.message_id WHERE s.id=$1`, id)
+	return item, err
+}
+
+func (q *safQueue) enqueue(message Message) (SafItem, error) {
+	messageId, err := q.messages.saveMessage(message)
+	if err != nil {
+		return SafItem{}, err
+	}
+	result, err := q.db.Exec(`INSERT INTO saf_item (message_id, switch, mti, status, created_at) VALUES ($1, $2, $3, $4, $5)`,
+		messageId, message.Switch, message.Mti, SAF_PENDING, time.Now().Format(time.RFC3339))
+	if err != nil {
+		return SafItem{}, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return SafItem{}, err
+	}
+	q.notify()
+	q.trigger()
+	return q.getItem(int(id))
+}
+
+func (q *safQueue) start() {
+	ctx, cancel := context.WithCancel(q.ctx)
+	q.cancel = cancel
+	q.done = make(chan struct{})
+	go q.run(ctx)
+}
+
+// stop returns once the advice being sent, if any, is settled.
+func (q *safQueue) stop() {
+	if q.cancel != nil {
+		q.cancel()
+		<-q.done
+	}
+	q.mu.Lock()
+	q.mu.Unlock()
+}
+
+func (q *safQueue) trigger() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *safQueue) run(ctx context.Context) {
+	defer close(q.done)
+	for {
+		interval := time.Duration(viper.GetInt("SAF_INTERVAL")) * time.Second
+		if interval <= 0 {
+			interval = defaultSafInterval
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake:
+		case <-time.After(interval):
+		}
+		q.flush(ctx)
+	}
+}
+
+// flush sends the pending advices in order, skipping the rest of a switch
+// after its first failure.
+func (q *safQueue) flush(ctx context.Context) {
+	items, err := q.getItems()
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return
+	}
+	unreachable := make(map[AtmSwitch]bool)
+	for _, item := range items {
+		if ctx.Err() != nil {
+			return
+		}
+		if item.Status != SAF_PENDING || unreachable[item.Switch] {
+			continue
+		}
+		err := q.forward(item.Id)
+		if err != nil {
+			unreachable[item.Switch] = true
+		}
+	}
+}
+
+// forward sends a repeat once a previous attempt went unanswered.
+func (q *safQueue) forward(id int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	item, err := q.getItem(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("advice %d is no longer queued", id)
+	}
+	if err != nil {
+		return err
+	}
+	defer q.notify()
+
+	message, err := q.messages.getMessage(item.MessageId)
+	if err != nil {
+		return q.fail(item, err, false)
+	}
+	sw, err := getAtmSwitch(message)
+	if err != nil {
+		return q.fail(item, err, false)
+	}
+	message.Mti = item.Mti
+	if item.Attempts > 0 {
+		message.Mti = repeatMti(item.Mti)
+	}
+	b, err := sw.pack(message)
+	if err != nil {
+		return q.fail(item, err, false)
+	}
+	response, err := q.send(item.Switch, sw, b)
+	if err != nil {
+		return q.fail(item, err, errors.Is(err, errNoResponse))
+	}
+	decoded, err := sw.decode(response)
+	if err != nil {
+		return q.fail(item, err, true)
+	}
+	expected, err := responseMti(item.Mti)
+	if err != nil {
+		return q.fail(item, err, true)
+	}
+	if decoded.Mti != expected {
+		return q.fail(item, fmt.Errorf("expected %s, received %s", expected, decoded.Mti), true)
+	}
+	responseCode := decoded.field(39)
+	log.Printf("%s advice %d acknowledged with %s, response code %s", item.Switch, item.Id, decoded.Mti, responseCode)
+	_, err = q.db.Exec("DELETE FROM saf_item WHERE id=$1", item.Id)
+	if err != nil {
+		return err
+	}
+	err = q.messages.saveResponseCode(item.MessageId, responseCode)
+	if err != nil {
+		return err
+	}
+	status, _ := reversalOutcome(AtmResponse{ResponseCode: responseCode}, nil)
+	return q.messages.settleReversalOf(item.MessageId, status, responseCode)
+}
+
+// fail counts only the attempts the host may have received.
+func (q *safQueue) fail(item SafItem, err error, attempted bool) error {
+	log.Error().Err(err).Msgf("failed to forward %s advice %d", item.Switch, item.Id)
+	if attempted {
+		item.Attempts++
+	}
+	status := SAF_PENDING
+	if max := viper.GetInt("SAF_MAX_ATTEMPTS"); max > 0 && item.Attempts >= max {
+		status = SAF_FAILED
+	}
+	_, dbErr := q.db.Exec(`UPDATE saf_item SET status=$1, attempts=$2, last_error=$3, last_attempt_at=$4 WHERE id=$5`,
+		status, item.Attempts, err.Error(), time.Now().Format(time.RFC3339), item.Id)
+	if dbErr == nil && status == SAF_FAILED {
+		dbErr = q.messages.settleReversalOf(item.MessageId, REVERSAL_FAILED, "")
+	}
+	if dbErr != nil {
+		log.Error().Err(dbErr).Msg("")
+	}
+	return err
+}
+
+// purge fails the reversals among the removed advices.
+func (q *safQueue) purge(id int) error {
+	defer q.notify()
+	items, err := q.getItems()
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if id > 0 && item.Id != id {
+			continue
+		}
+		_, err = q.db.Exec("DELETE FROM saf_item WHERE id=$1", item.Id)
+		if err != nil {
+			return err
+		}
+		err = q.messages.settleReversalOf(item.MessageId, REVERSAL_FAILED, "")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (q *safQueue) notify() {
+	items, err := q.getItems()
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return
+	}
+	runtime.EventsEmit(q.ctx, "saf", items)
+}
+
+// buildAdvice builds a request or reversal and turns it into its advice MTI.
+// A PIN change is refused as its repeats would lack the PIN blocks.
+func buildAdvice(message *Message, reversal bool) error {
+	if !reversal && message.Transaction == PIN_CHANGE {
+		return fmt.Errorf("%s cannot be sent as an advice", message.Transaction)
+	}
+	sw, err := getAtmSwitch(*message)
+	if err != nil {
+		return err
+	}
+	err = sw.build(message, reversal)
+	if err != nil {
+		return err
+	}
+	message.Mti, err = adviceMti(message.Mti)
+	return err
+}
+
+// SendAdviceMessage queues the message as a financial advice (0220).
+func (a *App) SendAdviceMessage(message Message) (SafItem, error) {
+	err := validateMessage(message)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return SafItem{}, err
+	}
+	err = buildAdvice(&message, false)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return SafItem{}, err
+	}
+	item, err := a.safQueue.enqueue(message)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return SafItem{}, err
+	}
+	return item, nil
+}
+
+// SendReversalAdvice queues a reversal advice (0420), pending until the host
+// acknowledges it.
+func (a *App) SendReversalAdvice(id int, options ReversalOptions) (SafItem, error) {
+	message, err := a.messageService.getMessage(id)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return SafItem{}, err
+	}
+	reversal, err := newReversal(message, options)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return SafItem{}, err
+	}
+	err = buildAdvice(&reversal, true)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return SafItem{}, err
+	}
+	err = a.messageService.claimReversal(message)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return SafItem{}, err
+	}
+	item, err := a.safQueue.enqueue(reversal)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		settleErr := a.messageService.settleReversal(message.Id, REVERSAL_FAILED, "")
+		if settleErr != nil {
+			log.Error().Err(settleErr).Msg("")
+		}
+		return SafItem{}, err
+	}
+	return item, nil
+}
+
+func (a *App) GetSafItems() ([]SafItem, error) {
+	items, err := a.safQueue.getItems()
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return nil, err
+	}
+	return items, nil
+}
+
+func (a *App) ResendSafItem(id int) error {
+	err := a.safQueue.forward(id)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	return nil
+}
+
+// PurgeSaf removes an advice from the queue, or every advice when id is 0.
+func (a *App) PurgeSaf(id int) error {
+	err := a.safQueue.purge(id)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestBuildAdvice(t *testing.T) {
+	tests := []struct {
+		transaction Transaction
+		reversal    bool
+		mti         string
+		wantErr     bool
+	}{
+		{transaction: WITHDRAW, mti: "1220"},
+		{transaction: WITHDRAW, reversal: true, mti: "1420"},
+		{transaction: PIN_CHANGE, reversal: true, mti: "1420"},
+		{transaction: PIN_CHANGE, wantErr: true},
+	}
+	for _, tt := range tests {
+		message := newTestMessage()
+		message.Transaction = tt.transaction
+		err := buildAdvice(&message, tt.reversal)
+		if (err != nil) != tt.wantErr || err == nil && message.Mti != tt.mti {
+			t.Errorf("buildAdvice(%s, %v) = %s, %v, want %s", tt.transaction, tt.reversal, message.Mti, err, tt.mti)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"golang.org/x/crypto/argon2"
+)
+
+type secretService struct {
+	db  *sqlx.DB
+	mu  sync.RWMutex
+	key []byte
+}
+
+type vault struct {
+	Salt     []byte `db:"salt"`
+	Verifier string `db:"verifier"`
+}
+
+type VaultStatus struct {
+	Initialized bool `json:"initialized"`
+	Unlocked    bool `json:"unlocked"`
+}
+
+var secretKeys = []string{"SSH_PASSPHRASE", "SSH_PRIVATE_KEY", "SSH_PASSWORD", "PROXY_PASSWORD", "CORTEX_ZPK", "NARADA_ZPK", "POSTBRIDGE_ZPK"}
+
+const (
+	redacted          = "********"
+	sealedPrefix      = "enc:v1:"
+	vaultCheck        = "atm-go vault"
+	minPasswordLength = 8
+	saltLength        = 16
+)
+
+var (
+	errVaultLocked = errors.New("secrets are locked, unlock them with the master password")
+	errVaultNotSet = errors.New("set a master password in the settings before saving secrets")
+)
+
+func isSecret(key string) bool {
+	return contains(secretKeys, key)
+}
+
+func redactSecrets(configs []Config) []Config {
+	for i, c := range configs {
+		if isSecret(c.Key) && len(c.Value) > 0 {
+			configs[i].Value = redacted
+		}
+	}
+	return configs
+}
+
+func deriveKey(password string, salt []byte) []byte {
+	return argon2.IDKey([]byte(password), salt, 1, 64*1024, 4, 32)
+}
+
+func seal(key []byte, plaintext string) (string, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func unseal(key []byte, value string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, sealedPrefix))
+	if err != nil {
+		return "", err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("sealed secret is too short")
+	}
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("unable to decrypt secret, wrong master password")
+	}
+	return string(plaintext), nil
+}
+
+func (s *secretService) getVault() (vault, bool, error) {
+	v := vault{}
+	err := s.db.Get(&v, "SELECT salt, verifier FROM vault WHERE id = 1")
+	if errors.Is(err, sql.ErrNoRows) {
+		return v, false, nil
+	}
+	return v, err == nil, err
+}
+
+func (s *secretService) status() (VaultStatus, error) {
+	_, initialized, err := s.getVault()
+	if err != nil {
+		return VaultStatus{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return VaultStatus{Initialized: initialized, Unlocked: s.key != nil}, nil
+}
+
+// setup creates the vault and encrypts every plaintext secret already stored
+// in the profiles.
+func (s *secretService) setup(password string) error {
+	if len(password) < minPasswordLength {
+		return fmt.Errorf("master password must be at least %d characters", minPasswordLength)
+	}
+	_, initialized, err := s.getVault()
+	if err != nil {
+		return err
+	}
+	if initialized {
+		return errors.New("master password is already set")
+	}
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	key := deriveKey(password, salt)
+	verifier, err := seal(key, vaultCheck)
+	if err != nil {
+		return err
+	}
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("INSERT INTO vault (id, salt, verifier) VALUES (1, $1, $2)", salt, verifier)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	err = sealPlaintexts(tx, key)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.key = key
+	s.mu.Unlock()
+	return nil
+}
+
+// sealPlaintexts encrypts the plaintext secrets stored before secrets were
+// encrypted.
+func sealPlaintexts(tx *sqlx.Tx, key []byte) error {
+	plaintexts := []struct {
+		Profile string `db:"profile"`
+		Key     string `db:"key"`
+		Value   string `db:"value"`
+	}{}
+	query, args, _ := sqlx.In(`SELECT profile, "key", "value" FROM profile_config WHERE "key" IN (?) AND "value" <> ''`, secretKeys)
+	err := tx.Select(&plaintexts, tx.Rebind(query), args...)
+	if err != nil {
+		return err
+	}
+	for _, p := range plaintexts {
+		if strings.HasPrefix(p.Value, sealedPrefix) {
+			continue
+		}
+		sealed, err := seal(key, p.Value)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`UPDATE profile_config SET "value" = $1 WHERE profile = $2 AND "key" = $3`, sealed, p.Profile, p.Key)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *secretService) unlock(password string) error {
+	v, initialized, err := s.getVault()
+	if err != nil {
+		return err
+	}
+	if !initialized {
+		return errors.New("master password is not set")
+	}
+	key := deriveKey(password, v.Salt)
+	check, err := unseal(key, v.Verifier)
+	if err != nil || check != vaultCheck {
+		return errors.New("wrong master password")
+	}
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	err = sealPlaintexts(tx, key)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.key = key
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *secretService) lock() {
+	s.mu.Lock()
+	s.key = nil
+	s.mu.Unlock()
+}
+
+// get decrypts a secret of the active profile.
+func (s *secretService) get(key string) (string, error) {
+	value := viper.GetString(key)
+	if len(value) == 0 {
+		return "", nil
+	}
+	if !strings.HasPrefix(value, sealedPrefix) {
+		return "", fmt.Errorf("%s is stored in plaintext, set a master password to encrypt it", key)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.key == nil {
+		return "", errVaultLocked
+	}
+	return unseal(s.key, value)
+}
+
+// sealConfigs encrypts secret values before they are stored. Redacted values
+// sent back by the settings form are left untouched.
+func (s *secretService) sealConfigs(configs []Config) ([]Config, error) {
+	sealed := make([]Config, 0, len(configs))
+	for _, c := range configs {
+		if isSecret(c.Key) && len(c.Value) > 0 {
+			if c.Value == redacted {
+				continue
+			}
+			s.mu.RLock()
+			key := s.key
+			s.mu.RUnlock()
+			if key == nil {
+				return nil, s.lockedError()
+			}
+			value, err := seal(key, c.Value)
+			if err != nil {
+				return nil, err
+			}
+			c.Value = value
+		}
+		sealed = append(sealed, c)
+	}
+	return sealed, nil
+}
+
+func (s *secretService) lockedError() error {
+	_, initialized, err := s.getVault()
+	if err != nil {
+		return err
+	}
+	if !initialized {
+		return errVaultNotSet
+	}
+	return errVaultLocked
+}
+
+func (a *App) GetVaultStatus() (VaultStatus, error) {
+	status, err := a.secretService.status()
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return VaultStatus{}, err
+	}
+	return status, nil
+}
+
+func (a *App) SetupVault(password string) error {
+	err := a.secretService.setup(password)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	profile, err := a.configService.getActiveProfile()
+	if err == nil {
+		err = a.configService.switchProfile(profile)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	return nil
+}
+
+func (a *App) UnlockVault(password string) error {
+	err := a.secretService.unlock(password)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	// reload the secrets sealed by the unlock
+	profile, err := a.configService.getActiveProfile()
+	if err == nil {
+		err = a.configService.switchProfile(profile)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	return nil
+}
+
+func (a *App) LockVault() {
+	a.secretService.lock()
+}
+
+func (a *App) ImportSshKey() error {
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{})
+	if err != nil || len(path) == 0 {
+		return err
+	}
+	key, err := os.ReadFile(path)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	return a.UpdateConfigs([]Config{{Key: "SSH_PRIVATE_KEY", Value: string(key)}})
+}
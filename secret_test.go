@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSealUnseal(t *testing.T) {
+	key := deriveKey("correct horse", []byte("0123456789abcdef"))
+	sealed, err := seal(key, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(sealed, sealedPrefix) || strings.Contains(sealed, "passphrase") {
+		t.Errorf("seal() = %s", sealed)
+	}
+	got, err := unseal(key, sealed)
+	if err != nil || got != "passphrase" {
+		t.Errorf("unseal() = %q, %v, want passphrase", got, err)
+	}
+	wrong := deriveKey("wrong horse", []byte("0123456789abcdef"))
+	if _, err := unseal(wrong, sealed); err == nil {
+		t.Error("unseal() with another key should fail")
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	configs := redactSecrets([]Config{
+		{Key: "SSH_PASSWORD", Value: "secret"},
+		{Key: "SSH_PASSPHRASE", Value: ""},
+		{Key: "SSH_USER", Value: "ops"},
+	})
+	want := []string{redacted, "", "ops"}
+	for i, c := range configs {
+		if c.Value != want[i] {
+			t.Errorf("%s = %q, want %q", c.Key, c.Value, want[i])
+		}
+	}
+}
+
+func TestVault(t *testing.T) {
+	s := &secretService{db: newTestDB(t)}
+	_, err := s.sealConfigs([]Config{{Key: "SSH_PASSWORD", Value: "secret"}})
+	if !errors.Is(err, errVaultNotSet) {
+		t.Errorf("sealConfigs() without a vault error = %v, want %v", err, errVaultNotSet)
+	}
+	_, err = s.db.Exec(`UPDATE profile_config SET "value" = 'plaintext' WHERE "key" = 'SSH_PASSWORD'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.setup("short"); err == nil {
+		t.Error("setup() with a short password should fail")
+	}
+	if err := s.setup("correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	var stored string
+	err = s.db.Get(&stored, `SELECT "value" FROM profile_config WHERE "key" = 'SSH_PASSWORD' LIMIT 1`)
+	if err != nil || !strings.HasPrefix(stored, sealedPrefix) {
+		t.Errorf("setup() left SSH_PASSWORD as %q, %v", stored, err)
+	}
+
+	s.lock()
+	_, err = s.sealConfigs([]Config{{Key: "SSH_PASSWORD", Value: "secret"}})
+	if !errors.Is(err, errVaultLocked) {
+		t.Errorf("sealConfigs() while locked error = %v, want %v", err, errVaultLocked)
+	}
+	if err := s.unlock("wrong horse"); err == nil {
+		t.Error("unlock() with a wrong password should fail")
+	}
+	if err := s.unlock("correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	configs, err := s.sealConfigs([]Config{
+		{Key: "SSH_PASSWORD", Value: "secret"},
+		{Key: "SSH_PASSPHRASE", Value: redacted},
+		{Key: "SSH_USER", Value: "ops"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configs) != 2 || configs[1].Value != "ops" {
+		t.Fatalf("sealConfigs() = %+v, want the redacted value dropped", configs)
+	}
+	if value, err := unseal(s.key, configs[0].Value); err != nil || value != "secret" {
+		t.Errorf("sealed SSH_PASSWORD = %q, %v, want secret", value, err)
+	}
+}
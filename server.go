@@ -0,0 +1,398 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const serverPromptTimeout = 2 * time.Minute
+
+// ServerRule answers the requests matching every non empty criterion.
+type ServerRule struct {
+	Id           int    `db:"id" json:"id,omitempty"`
+	Mti          string `db:"mti" json:"mti"`
+	ProcessCode  string `db:"process_code" json:"processCode"`
+	PanPrefix    string `db:"pan_prefix" json:"panPrefix"`
+	ResponseCode string `db:"response_code" json:"responseCode"`
+	Balance      string `db:"balance" json:"balance"`
+	Delay        int    `db:"delay" json:"delay"`
+}
+
+type ServerMessage struct {
+	Id      int64      `json:"id"`
+	Time    string     `json:"time"`
+	Remote  string     `json:"remote"`
+	Inbound bool       `json:"inbound"`
+	Message IsoMessage `json:"message"`
+	Rule    int        `json:"rule,omitempty"`
+	Error   string     `json:"error,omitempty"`
+}
+
+type ServerStatus struct {
+	Running     bool      `json:"running"`
+	Switch      AtmSwitch `json:"switch,omitempty"`
+	Port        int       `json:"port,omitempty"`
+	Interactive bool      `json:"interactive"`
+}
+
+type serverConn struct {
+	net.Conn
+	writeMu sync.Mutex
+}
+
+type hostServer struct {
+	ctx         context.Context
+	db          *sqlx.DB
+	mu          sync.Mutex
+	listener    net.Listener
+	atmSwitch   AtmSwitch
+	port        int
+	interactive bool
+	conns       map[*serverConn]struct{}
+	pending     map[int64]chan string
+	lastId      int64
+}
+
+func newHostServer(ctx context.Context, db *sqlx.DB) *hostServer {
+	return &hostServer{
+		ctx:     ctx,
+		db:      db,
+		conns:   make(map[*serverConn]struct{}),
+		pending: make(map[int64]chan string),
+	}
+}
+
+func (s *hostServer) getRules() ([]ServerRule, error) {
+	rules := []ServerRule{}
+	err := s.db.Select(&rules, "SELECT * FROM server_rule ORDER BY id")
+	return rules, err
+}
+
+// saveRules replaces every rule, keeping the given order.
+func (s *hostServer) saveRules(rules []ServerRule) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("DELETE FROM server_rule")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, r := range rules {
+		if len(r.ResponseCode) == 0 {
+			tx.Rollback()
+			return errors.New("response code is required")
+		}
+		_, err = tx.Exec(`INSERT INTO server_rule (mti, process_code, pan_prefix, response_code, balance, delay)
+			VALUES ($1, $2, $3, $4, $5, $6)`, r.Mti, r.ProcessCode, r.PanPrefix, r.ResponseCode, r.Balance, r.Delay)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func matchRule(rules []ServerRule, request IsoMessage) (ServerRule, bool) {
+	for _, r := range rules {
+		if len(r.Mti) > 0 && r.Mti != request.Mti {
+			continue
+		}
+		if !strings.HasPrefix(request.field(3), r.ProcessCode) {
+			continue
+		}
+		if !strings.HasPrefix(request.field(2), r.PanPrefix) {
+			continue
+		}
+		return r, true
+	}
+	return ServerRule{}, false
+}
+
+// echoedFields leave out PIN blocks, track data and original data elements.
+var echoedFields = []string{"2", "3", "4", "5", "6", "7", "10", "11", "12", "13", "15", "32", "37", "41", "42", "49", "50", "51", "70", "100", "102", "103"}
+
+func buildResponse(request IsoMessage, responseCode string, balance string) (IsoMessage, error) {
+	mti, err := responseMti(request.Mti)
+	if err != nil {
+		return IsoMessage{}, err
+	}
+	response := IsoMessage{Mti: mti, Fields: make(map[string]string, len(echoedFields)+2)}
+	for _, k := range echoedFields {
+		if v, ok := request.Fields[k]; ok {
+			response.Fields[k] = v
+		}
+	}
+	response.Fields["39"] = responseCode
+	if len(balance) > 0 {
+		response.Fields["54"] = balance
+	}
+	return response, nil
+}
+
+func (s *hostServer) start(atmSwitch AtmSwitch, port int, interactive bool) error {
+	if _, err := getAtmSwitch(Message{Switch: atmSwitch}); err != nil {
+		return err
+	}
+	framing, err := getFraming(atmSwitch)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener != nil {
+		return fmt.Errorf("server is already listening on port %d", s.port)
+	}
+	listener, err := net.Listen("tcp", net.JoinHostPort("", strconv.Itoa(port)))
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %d: %w", port, err)
+	}
+	s.listener = listener
+	s.atmSwitch = atmSwitch
+	s.port = port
+	s.interactive = interactive
+	log.Printf("%s server listening on %s", atmSwitch, listener.Addr())
+	go s.serve(listener, atmSwitch, framing)
+	return nil
+}
+
+func (s *hostServer) stop() error {
+	s.mu.Lock()
+	listener := s.listener
+	s.listener = nil
+	conns := s.conns
+	s.conns = make(map[*serverConn]struct{})
+	pending := s.pending
+	s.pending = make(map[int64]chan string)
+	s.mu.Unlock()
+	if listener == nil {
+		return errors.New("server is not running")
+	}
+	listener.Close()
+	for c := range conns {
+		c.Close()
+	}
+	for _, answer := range pending {
+		close(answer)
+	}
+	log.Print("server stopped")
+	return nil
+}
+
+func (s *hostServer) status() ServerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		return ServerStatus{}
+	}
+	return ServerStatus{Running: true, Switch: s.atmSwitch, Port: s.port, Interactive: s.interactive}
+}
+
+func (s *hostServer) serve(listener net.Listener, atmSwitch AtmSwitch, framing framing) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			log.Printf("server stopped accepting connections: %v", err)
+			return
+		}
+		c := &serverConn{Conn: conn}
+		s.mu.Lock()
+		if s.listener != listener {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[c] = struct{}{}
+		s.mu.Unlock()
+		log.Printf("server accepted connection from %s", conn.RemoteAddr())
+		go s.handle(c, atmSwitch, framing)
+	}
+}
+
+func (s *hostServer) handle(conn *serverConn, atmSwitch AtmSwitch, framing framing) {
+	defer func() {
+		conn.Close()
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		log.Printf("server connection from %s closed", conn.RemoteAddr())
+	}()
+	sw, _ := getAtmSwitch(Message{Switch: atmSwitch})
+	for {
+		frame, err := framing.read(conn)
+		if err != nil {
+			return
+		}
+		request, err := sw.decode(frame)
+		inbound := s.record(conn, true, request, 0, err)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to decode request")
+			continue
+		}
+		go s.reply(conn, sw, framing, inbound)
+	}
+}
+
+func (s *hostServer) reply(conn *serverConn, sw atmSwitch, framing framing, inbound ServerMessage) {
+	request := inbound.Message
+	rules, err := s.getRules()
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return
+	}
+	rule, matched := matchRule(rules, request)
+	if !matched {
+		s.mu.Lock()
+		interactive := s.interactive
+		s.mu.Unlock()
+		if !interactive {
+			log.Printf("no rule matched request %d, not replying", inbound.Id)
+			return
+		}
+		responseCode, err := s.prompt(inbound)
+		if err != nil {
+			log.Error().Err(err).Msg("")
+			return
+		}
+		if len(responseCode) == 0 {
+			log.Printf("request %d dropped", inbound.Id)
+			return
+		}
+		rule = ServerRule{ResponseCode: responseCode}
+	}
+
+	time.Sleep(time.Duration(rule.Delay) * time.Millisecond)
+	response, err := buildResponse(request, rule.ResponseCode, rule.Balance)
+	var b []byte
+	if err == nil {
+		b, err = sw.encode(response)
+	}
+	if err == nil {
+		b, err = framing.frame(b)
+	}
+	if err == nil {
+		conn.writeMu.Lock()
+		_, err = conn.Write(b)
+		conn.writeMu.Unlock()
+	}
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to reply to request %d", inbound.Id)
+	}
+	s.record(conn, false, response, rule.Id, err)
+}
+
+// prompt asks the user for a response code. An empty one drops the request.
+func (s *hostServer) prompt(inbound ServerMessage) (string, error) {
+	answer := make(chan string, 1)
+	s.mu.Lock()
+	s.pending[inbound.Id] = answer
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, inbound.Id)
+		s.mu.Unlock()
+	}()
+
+	runtime.EventsEmit(s.ctx, "server:request", inbound)
+	select {
+	case responseCode, ok := <-answer:
+		if !ok {
+			return "", errors.New("server stopped")
+		}
+		return responseCode, nil
+	case <-time.After(serverPromptTimeout):
+		return "", fmt.Errorf("no answer for request %d", inbound.Id)
+	}
+}
+
+func (s *hostServer) answer(id int64, responseCode string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	answer, ok := s.pending[id]
+	if !ok {
+		return fmt.Errorf("request %d is not waiting for an answer", id)
+	}
+	answer <- responseCode
+	delete(s.pending, id)
+	return nil
+}
+
+// record emits a received or sent message as a "server:message" event.
+func (s *hostServer) record(conn net.Conn, inbound bool, message IsoMessage, rule int, err error) ServerMessage {
+	s.mu.Lock()
+	s.lastId++
+	m := ServerMessage{
+		Id:      s.lastId,
+		Time:    time.Now().Format(time.RFC3339),
+		Remote:  conn.RemoteAddr().String(),
+		Inbound: inbound,
+		Message: message,
+		Rule:    rule,
+	}
+	s.mu.Unlock()
+	if err != nil {
+		m.Error = err.Error()
+	}
+	runtime.EventsEmit(s.ctx, "server:message", m)
+	return m
+}
+
+func (a *App) StartServer(atmSwitch AtmSwitch, port int, interactive bool) error {
+	err := a.hostServer.start(atmSwitch, port, interactive)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	return nil
+}
+
+func (a *App) StopServer() error {
+	err := a.hostServer.stop()
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	return nil
+}
+
+func (a *App) GetServerStatus() ServerStatus {
+	return a.hostServer.status()
+}
+
+func (a *App) GetServerRules() ([]ServerRule, error) {
+	rules, err := a.hostServer.getRules()
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (a *App) SaveServerRules(rules []ServerRule) error {
+	err := a.hostServer.saveRules(rules)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	return nil
+}
+
+func (a *App) AnswerServerRequest(id int64, responseCode string) error {
+	err := a.hostServer.answer(id, responseCode)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	return nil
+}
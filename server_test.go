@@ -0,0 +1,73 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchRule(t *testing.T) {
+	rules := []ServerRule{
+		{Id: 1, Mti: "0200", ProcessCode: "31", ResponseCode: "00"},
+		{Id: 2, ProcessCode: "01", PanPrefix: "4111", ResponseCode: "51"},
+		{Id: 3, ResponseCode: "05"},
+	}
+	tests := []struct {
+		request IsoMessage
+		want    int
+	}{
+		{IsoMessage{Mti: "0200", Fields: map[string]string{"2": "4111111111111111", "3": "311000"}}, 1},
+		{IsoMessage{Mti: "0100", Fields: map[string]string{"2": "4111111111111111", "3": "311000"}}, 3},
+		{IsoMessage{Mti: "0200", Fields: map[string]string{"2": "4111111111111111", "3": "011000"}}, 2},
+		{IsoMessage{Mti: "0200", Fields: map[string]string{"2": "5111111111111111", "3": "011000"}}, 3},
+	}
+	for _, tt := range tests {
+		rule, ok := matchRule(rules, tt.request)
+		if !ok || rule.Id != tt.want {
+			t.Errorf("matchRule(%+v) = rule %d, want %d", tt.request, rule.Id, tt.want)
+		}
+	}
+	if _, ok := matchRule(rules[:2], IsoMessage{Mti: "0800"}); ok {
+		t.Error("matchRule() of 0800 should not match")
+	}
+}
+
+func TestResponseMti(t *testing.T) {
+	tests := map[string]string{"0200": "0210", "0100": "0110", "0420": "0430", "0800": "0810", "1200": "1210", "1804": "1814", "0421": "0430"}
+	for mti, want := range tests {
+		if got, err := responseMti(mti); err != nil || got != want {
+			t.Errorf("responseMti(%s) = %s, %v, want %s", mti, got, err, want)
+		}
+	}
+	for _, mti := range []string{"0210", "020", "02a0"} {
+		if _, err := responseMti(mti); err == nil {
+			t.Errorf("responseMti(%s) should fail", mti)
+		}
+	}
+}
+
+func TestBuildResponse(t *testing.T) {
+	request := IsoMessage{Mti: "0200", Fields: map[string]string{
+		"2":  "4111111111111111",
+		"3":  "311000",
+		"11": "123456",
+		"35": "4111111111111111=2512",
+		"37": "000000123456",
+		"52": "2A3D408A1977DDE9",
+		"90": "020012345610191200000000001234",
+	}}
+	response, err := buildResponse(request, "00", "1002608C000000123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := IsoMessage{Mti: "0210", Fields: map[string]string{
+		"2":  "4111111111111111",
+		"3":  "311000",
+		"11": "123456",
+		"37": "000000123456",
+		"39": "00",
+		"54": "1002608C000000123456",
+	}}
+	if !reflect.DeepEqual(response, want) {
+		t.Errorf("buildResponse() = %+v, want %+v", response, want)
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+type SshAuthMethod string
+
+const (
+	AGENT_AUTH                SshAuthMethod = "agent"
+	PUBLICKEY_AUTH            SshAuthMethod = "publickey"
+	PASSWORD_AUTH             SshAuthMethod = "password"
+	KEYBOARD_INTERACTIVE_AUTH SshAuthMethod = "keyboard-interactive"
+)
+
+const challengeTimeout = 2 * time.Minute
+
+type sshAuthService struct {
+	ctx     context.Context
+	secrets *secretService
+	mu      sync.Mutex
+	answers chan []string
+}
+
+type SshChallenge struct {
+	User        string   `json:"user"`
+	Instruction string   `json:"instruction"`
+	Questions   []string `json:"questions"`
+	Echos       []bool   `json:"echos"`
+}
+
+// authMethods builds the methods listed in SSH_AUTH_METHODS. The returned
+// func closes the SSH agent connection after the handshake.
+func (s *sshAuthService) authMethods() ([]ssh.AuthMethod, func(), error) {
+	var methods []ssh.AuthMethod
+	var closers []func()
+	var firstErr error
+	for _, name := range strings.Split(viper.GetString("SSH_AUTH_METHODS"), ",") {
+		var method ssh.AuthMethod
+		var err error
+		switch SshAuthMethod(strings.TrimSpace(name)) {
+		case AGENT_AUTH:
+			var conn net.Conn
+			method, conn, err = agentAuth()
+			if conn != nil {
+				closers = append(closers, func() { conn.Close() })
+			}
+		case PUBLICKEY_AUTH:
+			method, err = s.publicKeyAuth()
+		case PASSWORD_AUTH:
+			method = ssh.PasswordCallback(s.password)
+		case KEYBOARD_INTERACTIVE_AUTH:
+			method = ssh.KeyboardInteractive(s.challenge)
+		case "":
+			continue
+		default:
+			err = fmt.Errorf("unknown SSH auth method %q", name)
+		}
+		if err != nil {
+			log.Error().Err(err).Msgf("skipping SSH auth method %s", name)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		methods = append(methods, method)
+	}
+	closeAll := func() {
+		for _, c := range closers {
+			c()
+		}
+	}
+	if len(methods) == 0 {
+		closeAll()
+		if firstErr == nil {
+			firstErr = errors.New("missing SSH_AUTH_METHODS, please configure it in the settings")
+		}
+		return nil, nil, firstErr
+	}
+	return methods, closeAll, nil
+}
+
+func agentAuth() (ssh.AuthMethod, net.Conn, error) {
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if len(socket) == 0 {
+		return nil, nil, errors.New("SSH_AUTH_SOCK is not set, is the SSH agent running?")
+	}
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to SSH agent: %w", err)
+	}
+	return ssh.PublicKeysCallback(agent.NewClient(conn).Signers), conn, nil
+}
+
+func (s *sshAuthService) publicKeyAuth() (ssh.AuthMethod, error) {
+	key, err := s.secrets.get("SSH_PRIVATE_KEY")
+	if err != nil {
+		return nil, err
+	}
+	if len(key) == 0 {
+		sshKey := viper.GetString("SSH_KEY")
+		if len(sshKey) == 0 {
+			return nil, errors.New("missing SSH_KEY, please configure it in the settings")
+		}
+		b, err := os.ReadFile(sshKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read private key: %w", err)
+		}
+		key = string(b)
+	}
+
+	passPhrase, err := s.secrets.get("SSH_PASSPHRASE")
+	if err != nil {
+		return nil, err
+	}
+	var signer ssh.Signer
+
+	if len(passPhrase) > 0 {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(key), []byte(passPhrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey([]byte(key))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	certificate := viper.GetString("SSH_CERTIFICATE")
+	if len(certificate) == 0 {
+		return ssh.PublicKeys(signer), nil
+	}
+	certSigner, err := certificateSigner(certificate, signer)
+	if err != nil {
+		return nil, err
+	}
+	return ssh.PublicKeys(certSigner, signer), nil
+}
+
+func certificateSigner(file string, signer ssh.Signer) (ssh.Signer, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate: %w", err)
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	cert, ok := pub.(*ssh.Certificate)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an OpenSSH certificate", file)
+	}
+	return ssh.NewCertSigner(cert, signer)
+}
+
+func (s *sshAuthService) password() (string, error) {
+	password, err := s.secrets.get("SSH_PASSWORD")
+	if err != nil || len(password) > 0 {
+		return password, err
+	}
+	answers, err := s.challenge(viper.GetString("SSH_USERNAME"), "", []string{"Password:"}, []bool{false})
+	if err != nil {
+		return "", err
+	}
+	return answers[0], nil
+}
+
+// challenge asks the user for keyboard-interactive answers.
+func (s *sshAuthService) challenge(user, instruction string, questions []string, echos []bool) ([]string, error) {
+	if len(questions) == 0 {
+		return []string{}, nil
+	}
+	s.mu.Lock()
+	if s.answers != nil {
+		s.mu.Unlock()
+		return nil, errors.New("another SSH challenge is pending")
+	}
+	answers := make(chan []string, 1)
+	s.answers = answers
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		if s.answers == answers {
+			s.answers = nil
+		}
+		s.mu.Unlock()
+	}()
+
+	runtime.EventsEmit(s.ctx, "ssh:challenge", SshChallenge{
+		User:        user,
+		Instruction: instruction,
+		Questions:   questions,
+		Echos:       echos,
+	})
+	select {
+	case a := <-answers:
+		if len(a) == 0 {
+			return nil, errors.New("SSH challenge was cancelled")
+		}
+		if len(a) != len(questions) {
+			return nil, fmt.Errorf("expected %d answers, got %d", len(questions), len(a))
+		}
+		return a, nil
+	case <-time.After(challengeTimeout):
+		return nil, errors.New("no answer for the SSH challenge")
+	}
+}
+
+func (s *sshAuthService) respond(answers []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.answers == nil {
+		return errors.New("no SSH challenge is pending")
+	}
+	s.answers <- answers
+	s.answers = nil
+	return nil
+}
+
+func (a *App) AnswerSshChallenge(answers []string) error {
+	err := a.sshAuthService.respond(answers)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+type CertificateInfo struct {
+	Subject   string `json:"subject"`
+	Issuer    string `json:"issuer"`
+	NotBefore string `json:"notBefore"`
+	NotAfter  string `json:"notAfter"`
+	SHA256    string `json:"sha256"`
+	Pin       string `json:"pin"`
+}
+
+type ConnectionTest struct {
+	Address      string            `json:"address"`
+	Tls          bool              `json:"tls"`
+	Version      string            `json:"version,omitempty"`
+	CipherSuite  string            `json:"cipherSuite,omitempty"`
+	ServerName   string            `json:"serverName,omitempty"`
+	Certificates []CertificateInfo `json:"certificates,omitempty"`
+}
+
+// getTlsConfig returns nil when <SWITCH>_TLS is not enabled.
+func getTlsConfig(atmSwitch AtmSwitch) (*tls.Config, error) {
+	key := func(name string) string {
+		return fmt.Sprintf("%s_%s", atmSwitch, name)
+	}
+	if !viper.GetBool(key("TLS")) {
+		return nil, nil
+	}
+	config := &tls.Config{
+		ServerName: viper.GetString(key("TLS_SERVER_NAME")),
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if version := viper.GetString(key("TLS_MIN_VERSION")); len(version) > 0 {
+		v, ok := tlsVersions[version]
+		if !ok {
+			return nil, fmt.Errorf("invalid %s %q, expected 1.0, 1.1, 1.2 or 1.3", key("TLS_MIN_VERSION"), version)
+		}
+		config.MinVersion = v
+	}
+
+	if ca := viper.GetString(key("TLS_CA")); len(ca) > 0 {
+		pem, err := os.ReadFile(ca)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", ca)
+		}
+		config.RootCAs = pool
+	}
+
+	cert := viper.GetString(key("TLS_CERT"))
+	certKey := viper.GetString(key("TLS_KEY"))
+	if len(cert) > 0 || len(certKey) > 0 {
+		if len(certKey) == 0 {
+			certKey = cert
+		}
+		pair, err := tls.LoadX509KeyPair(cert, certKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{pair}
+	}
+
+	var pins []string
+	for _, pin := range strings.Split(viper.GetString(key("TLS_PIN")), ",") {
+		pin = strings.TrimPrefix(strings.TrimSpace(pin), "sha256/")
+		if len(pin) > 0 {
+			pins = append(pins, pin)
+		}
+	}
+	if len(pins) > 0 {
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			return verifyPins(state.PeerCertificates, pins)
+		}
+	}
+	return config, nil
+}
+
+// spkiPin is the base64 SHA-256 of the certificate public key, as used by
+// HPKP and curl --pinnedpubkey.
+func spkiPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// verifyPins accepts the chain if any of its certificates matches a pin.
+func verifyPins(certs []*x509.Certificate, pins []string) error {
+	for _, cert := range certs {
+		if contains(pins, spkiPin(cert)) {
+			return nil
+		}
+	}
+	return errors.New("no certificate in the chain matches the pinned public keys")
+}
+
+func (e endpoint) handshake(conn net.Conn, address string) (net.Conn, error) {
+	config := e.tls.Clone()
+	if len(config.ServerName) == 0 {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		config.ServerName = host
+	}
+	tlsConn := tls.Client(conn, config)
+	tlsConn.SetDeadline(time.Now().Add(e.timeout))
+	err := tlsConn.Handshake()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("TLS handshake with %s failed: %w", address, err)
+	}
+	tlsConn.SetDeadline(time.Time{})
+	return tlsConn, nil
+}
+
+func describeConnection(conn net.Conn) ConnectionTest {
+	test := ConnectionTest{Address: conn.RemoteAddr().String()}
+	tlsConn, ok := conn.(*tls.Conn)
+	if !ok {
+		return test
+	}
+	state := tlsConn.ConnectionState()
+	test.Tls = true
+	test.Version = tlsVersionName(state.Version)
+	test.CipherSuite = tls.CipherSuiteName(state.CipherSuite)
+	test.ServerName = state.ServerName
+	for _, cert := range state.PeerCertificates {
+		sum := sha256.Sum256(cert.Raw)
+		test.Certificates = append(test.Certificates, CertificateInfo{
+			Subject:   cert.Subject.String(),
+			Issuer:    cert.Issuer.String(),
+			NotBefore: cert.NotBefore.Format(time.RFC3339),
+			NotAfter:  cert.NotAfter.Format(time.RFC3339),
+			SHA256:    hex.EncodeToString(sum[:]),
+			Pin:       "sha256/" + spkiPin(cert),
+		})
+	}
+	return test
+}
+
+func tlsVersionName(version uint16) string {
+	for name, v := range tlsVersions {
+		if v == version {
+			return "TLS " + name
+		}
+	}
+	return fmt.Sprintf("0x%04x", version)
+}
+
+// TestConnection connects to a switch without sending a message.
+func (a *App) TestConnection(atmSwitch AtmSwitch) (ConnectionTest, error) {
+	d, err := newDialer(a.secretService, a.tunnelManager)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return ConnectionTest{}, err
+	}
+	endpoint, err := getEndpoint(atmSwitch)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return ConnectionTest{}, err
+	}
+	conn, err := endpoint.dial(d)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return ConnectionTest{}, err
+	}
+	defer conn.Close()
+	return describeConnection(conn), nil
+}
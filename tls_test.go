@@ -0,0 +1,123 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// newTestCertificate returns a self-signed certificate for localhost and
+// the path of its PEM file.
+func newTestCertificate(t *testing.T) (tls.Certificate, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	err = os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, _ := x509.ParseCertificate(der)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, path
+}
+
+func setTlsSettings(t *testing.T, settings map[string]string) {
+	for k, v := range settings {
+		viper.Set(k, v)
+	}
+	t.Cleanup(func() {
+		for k := range settings {
+			viper.Set(k, nil)
+		}
+	})
+}
+
+func TestTlsMinVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    uint16
+		wantErr bool
+	}{
+		{version: "", want: tls.VersionTLS12},
+		{version: "1.0", want: tls.VersionTLS10},
+		{version: "1.3", want: tls.VersionTLS13},
+		{version: "1.4", wantErr: true},
+		{version: "TLSv1.2", wantErr: true},
+	}
+	for _, tt := range tests {
+		setTlsSettings(t, map[string]string{"CORTEX_TLS": "true", "CORTEX_TLS_MIN_VERSION": tt.version})
+		config, err := getTlsConfig(CORTEX)
+		if (err != nil) != tt.wantErr || err == nil && config.MinVersion != tt.want {
+			t.Errorf("TLS_MIN_VERSION %q = %v, %v, want %x", tt.version, config, err, tt.want)
+		}
+	}
+	setTlsSettings(t, map[string]string{"CORTEX_TLS": "false"})
+	if config, err := getTlsConfig(CORTEX); config != nil || err != nil {
+		t.Errorf("getTlsConfig() with TLS disabled = %v, %v", config, err)
+	}
+}
+
+func TestTlsPin(t *testing.T) {
+	cert, ca := newTestCertificate(t)
+	pin := spkiPin(cert.Leaf)
+	tests := []struct {
+		pins    string
+		wantErr bool
+	}{
+		{pins: ""},
+		{pins: "sha256/" + pin},
+		{pins: "sha256/AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=, " + pin},
+		{pins: "sha256/AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=", wantErr: true},
+	}
+	for _, tt := range tests {
+		setTlsSettings(t, map[string]string{"CORTEX_TLS": "true", "CORTEX_TLS_CA": ca, "CORTEX_TLS_PIN": tt.pins})
+		config, err := getTlsConfig(CORTEX)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client, server := net.Pipe()
+		go func() {
+			tls.Server(server, &tls.Config{Certificates: []tls.Certificate{cert}}).Handshake()
+			server.Close()
+		}()
+		e := endpoint{tls: config, timeout: 5 * time.Second}
+		conn, err := e.handshake(client, "localhost:50122")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("handshake() with pins %q error = %v, wantErr %v", tt.pins, err, tt.wantErr)
+		}
+		if err == nil {
+			if got := describeConnection(conn).Certificates[0].Pin; got != "sha256/"+pin {
+				t.Errorf("describeConnection() pin = %s, want sha256/%s", got, pin)
+			}
+			conn.Close()
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ReconciliationRequest is a cut-off window formatted as 2006-01-02T15:04.
+type ReconciliationRequest struct {
+	Switch                   AtmSwitch `json:"switch"`
+	From                     string    `json:"from"`
+	To                       string    `json:"to"`
+	CurrencyCode             Currency  `json:"currencyCode"`
+	AcquiringInstitutionCode string    `json:"acquiringInstitutionCode"`
+	Advice                   bool      `json:"advice"`
+}
+
+type ReconciliationTotals struct {
+	CreditsNumber         int     `json:"creditsNumber"`
+	CreditsReversalNumber int     `json:"creditsReversalNumber"`
+	DebitsNumber          int     `json:"debitsNumber"`
+	DebitsReversalNumber  int     `json:"debitsReversalNumber"`
+	CreditsAmount         float64 `json:"creditsAmount"`
+	CreditsReversalAmount float64 `json:"creditsReversalAmount"`
+	DebitsAmount          float64 `json:"debitsAmount"`
+	DebitsReversalAmount  float64 `json:"debitsReversalAmount"`
+	NetAmount             float64 `json:"netAmount"`
+}
+
+type ReconciliationResult struct {
+	Request        IsoMessage           `json:"request"`
+	Response       IsoMessage           `json:"response"`
+	Local          ReconciliationTotals `json:"local"`
+	Host           ReconciliationTotals `json:"host"`
+	HostTotals     bool                 `json:"hostTotals"`
+	ResponseCode   string               `json:"responseCode"`
+	SettlementCode string               `json:"settlementCode"`
+	Differences    []string             `json:"differences"`
+}
+
+// totalsFields follow the order of ReconciliationTotals.
+var totalsFields = []struct {
+	field  int
+	name   string
+	number func(t *ReconciliationTotals) *int
+	amount func(t *ReconciliationTotals) *float64
+}{
+	{74, "credits number", func(t *ReconciliationTotals) *int { return &t.CreditsNumber }, nil},
+	{75, "credits reversal number", func(t *ReconciliationTotals) *int { return &t.CreditsReversalNumber }, nil},
+	{76, "debits number", func(t *ReconciliationTotals) *int { return &t.DebitsNumber }, nil},
+	{77, "debits reversal number", func(t *ReconciliationTotals) *int { return &t.DebitsReversalNumber }, nil},
+	{86, "credits amount", nil, func(t *ReconciliationTotals) *float64 { return &t.CreditsAmount }},
+	{87, "credits reversal amount", nil, func(t *ReconciliationTotals) *float64 { return &t.CreditsReversalAmount }},
+	{88, "debits amount", nil, func(t *ReconciliationTotals) *float64 { return &t.DebitsAmount }},
+	{89, "debits reversal amount", nil, func(t *ReconciliationTotals) *float64 { return &t.DebitsReversalAmount }},
+}
+
+func parseCutOff(value string) (string, error) {
+	t, err := time.Parse("2006-01-02T15:04", value)
+	if err != nil {
+		return "", fmt.Errorf("invalid cut-off %q", value)
+	}
+	return t.Format("060102150405"), nil
+}
+
+func (s *messageService) getTotalsMessages(request ReconciliationRequest) ([]Message, error) {
+	from, err := parseCutOff(request.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseCutOff(request.To)
+	if err != nil {
+		return nil, err
+	}
+	messages := []Message{}
+	err = s.db.Select(&messages, `SELECT * FROM atm_message
+		WHERE switch=$1 AND local_transaction_date_time BETWEEN $2 AND $3 AND currency_code=$4
+		AND ($5 = '' OR acquiring_institution_code=$5)
+		ORDER BY id`, request.Switch, from, to, request.CurrencyCode, request.AcquiringInstitutionCode)
+	return messages, err
+}
+
+// computeTotals counts approved messages: credits by their 2x processing
+// code, reversals by their x4xx MTI and debits otherwise. Holds and deposit
+// requests only count once completed.
+func computeTotals(messages []Message, currency Currency) ReconciliationTotals {
+	var totals ReconciliationTotals
+	var credits, creditsReversal, debits, debitsReversal int64
+	for _, m := range messages {
+		if !approved(m.ResponseCode) {
+			continue
+		}
+		if isHold(m.Transaction) || (isDeposit(m.Transaction) && m.AuthorizationId == 0) {
+			continue
+		}
+		reversal := len(m.Mti) == 4 && m.Mti[1] == '4'
+		amount := minorUnits(m.TransactionAmount, currency)
+		if reversal {
+			amount -= minorUnits(m.ReplacementAmount, currency)
+		}
+		if amount == 0 {
+			continue
+		}
+		credit := strings.HasPrefix(m.ProcessCode, "2")
+		switch {
+		case credit && reversal:
+			totals.CreditsReversalNumber++
+			creditsReversal += amount
+		case credit:
+			totals.CreditsNumber++
+			credits += amount
+		case reversal:
+			totals.DebitsReversalNumber++
+			debitsReversal += amount
+		default:
+			totals.DebitsNumber++
+			debits += amount
+		}
+	}
+	totals.CreditsAmount = fromMinorUnits(credits, currency)
+	totals.CreditsReversalAmount = fromMinorUnits(creditsReversal, currency)
+	totals.DebitsAmount = fromMinorUnits(debits, currency)
+	totals.DebitsReversalAmount = fromMinorUnits(debitsReversal, currency)
+	totals.NetAmount = fromMinorUnits((debits-debitsReversal)-(credits-creditsReversal), currency)
+	return totals
+}
+
+// netSettlement formats field 97, C when the acquirer is owed.
+func netSettlement(amount float64, currency Currency) string {
+	sign := "C"
+	if amount < 0 {
+		sign = "D"
+		amount = -amount
+	}
+	return sign + padLeftWithZeros(strconv.FormatInt(minorUnits(amount, currency), 10), 16)
+}
+
+func buildReconciliation(sw atmSwitch, request ReconciliationRequest, totals ReconciliationTotals) IsoMessage {
+	t := generateTransmissionDateTime()
+	message := IsoMessage{Mti: sw.reconciliationMti(request.Advice), Fields: map[string]string{
+		"7":  t,
+		"11": generateStan(),
+		"15": t[:4],
+		"50": string(request.CurrencyCode),
+		"97": netSettlement(totals.NetAmount, request.CurrencyCode),
+	}}
+	if len(request.AcquiringInstitutionCode) > 0 {
+		message.Fields["32"] = padLeftWithZeros(request.AcquiringInstitutionCode, 10)
+	}
+	for _, f := range totalsFields {
+		key := strconv.Itoa(f.field)
+		if f.number != nil {
+			message.Fields[key] = padLeftWithZeros(strconv.Itoa(*f.number(&totals)), 10)
+		} else {
+			message.Fields[key] = padLeftWithZeros(strconv.FormatInt(minorUnits(*f.amount(&totals), request.CurrencyCode), 10), 16)
+		}
+	}
+	return message
+}
+
+// compareTotals lists where the host totals differ from the local ones.
+func compareTotals(response IsoMessage, local ReconciliationTotals, currency Currency) (ReconciliationTotals, bool, []string) {
+	var host ReconciliationTotals
+	found := false
+	differences := []string{}
+	for _, f := range totalsFields {
+		value := response.field(f.field)
+		if len(value) == 0 {
+			continue
+		}
+		found = true
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			differences = append(differences, fmt.Sprintf("%s: invalid host value %q", f.name, value))
+			continue
+		}
+		if f.number != nil {
+			*f.number(&host) = int(n)
+			if *f.number(&host) != *f.number(&local) {
+				differences = append(differences, fmt.Sprintf("%s: local %d, host %d", f.name, *f.number(&local), n))
+			}
+			continue
+		}
+		*f.amount(&host) = fromMinorUnits(n, currency)
+		if !sameAmount(*f.amount(&host), *f.amount(&local), currency) {
+			differences = append(differences, fmt.Sprintf("%s: local %s, host %s", f.name, formatAmount(*f.amount(&local), currency), formatAmount(*f.amount(&host), currency)))
+		}
+	}
+	if found {
+		net := (minorUnits(host.DebitsAmount, currency) - minorUnits(host.DebitsReversalAmount, currency)) - (minorUnits(host.CreditsAmount, currency) - minorUnits(host.CreditsReversalAmount, currency))
+		host.NetAmount = fromMinorUnits(net, currency)
+	}
+	return host, found, differences
+}
+
+func (a *App) GetReconciliationTotals(request ReconciliationRequest) (ReconciliationTotals, error) {
+	messages, err := a.messageService.getTotalsMessages(request)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return ReconciliationTotals{}, err
+	}
+	return computeTotals(messages, request.CurrencyCode), nil
+}
+
+// SendReconciliation sends the local totals of the cut-off window.
+func (a *App) SendReconciliation(request ReconciliationRequest) (ReconciliationResult, error) {
+	sw, err := getAtmSwitch(Message{Switch: request.Switch})
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return ReconciliationResult{}, err
+	}
+	messages, err := a.messageService.getTotalsMessages(request)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return ReconciliationResult{}, err
+	}
+	result := ReconciliationResult{Local: computeTotals(messages, request.CurrencyCode)}
+	result.Request = buildReconciliation(sw, request, result.Local)
+	b, err := sw.encode(result.Request)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return ReconciliationResult{}, err
+	}
+	response, err := exchange(a, request.Switch, sw, b)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return ReconciliationResult{}, err
+	}
+	result.Response, err = sw.decode(response)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return ReconciliationResult{}, err
+	}
+	result.ResponseCode = result.Response.field(39)
+	result.SettlementCode = result.Response.field(66)
+	result.Host, result.HostTotals, result.Differences = compareTotals(result.Response, result.Local, request.CurrencyCode)
+	return result, nil
+}
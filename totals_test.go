@@ -0,0 +1,51 @@
+package main
+
+import "testing"
+
+func TestComputeTotals(t *testing.T) {
+	messages := []Message{
+		{Id: 1, Transaction: WITHDRAW, Mti: "0200", ProcessCode: "011000", TransactionAmount: 1000, ResponseCode: "00"},
+		{Id: 2, Transaction: WITHDRAW, Mti: "0200", ProcessCode: "011000", TransactionAmount: 700, ResponseCode: "51"},
+		{Id: 3, Transaction: WITHDRAW, Mti: "0200", ProcessCode: "011000", TransactionAmount: 300, ResponseCode: "000"},
+		{Id: 4, ParentId: 3, Transaction: WITHDRAW, Mti: "0420", ProcessCode: "011000", TransactionAmount: 300, ReplacementAmount: 100, ResponseCode: "00"},
+		{Id: 5, ParentId: 1, Transaction: WITHDRAW, Mti: "0420", ProcessCode: "011000", TransactionAmount: 1000, ResponseCode: "12"},
+		{Id: 6, Transaction: DEPOSIT, Mti: "0200", ProcessCode: "210000", TransactionAmount: 500, ResponseCode: "00"},
+		{Id: 7, Transaction: DEPOSIT, Mti: "0220", ProcessCode: "210000", TransactionAmount: 500, ResponseCode: "00", AuthorizationId: 6},
+		{Id: 8, Transaction: PRE_AUTH, Mti: "0100", ProcessCode: "001000", TransactionAmount: 2000, ResponseCode: "00"},
+	}
+	want := ReconciliationTotals{
+		CreditsNumber:        1,
+		CreditsAmount:        500,
+		DebitsNumber:         2,
+		DebitsAmount:         1300,
+		DebitsReversalNumber: 1,
+		DebitsReversalAmount: 200,
+		NetAmount:            600,
+	}
+	got := computeTotals(messages, PHP)
+	if got != want {
+		t.Errorf("computeTotals() = %+v, want %+v", got, want)
+	}
+
+	yen := []Message{{Transaction: WITHDRAW, Mti: "0200", ProcessCode: "011000", TransactionAmount: 1300.4, ResponseCode: "00"}}
+	if got := computeTotals(yen, "392"); got.DebitsAmount != 1300 {
+		t.Errorf("computeTotals() in JPY = %v, want 1300", got.DebitsAmount)
+	}
+}
+
+func TestNetSettlement(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		currency Currency
+		want     string
+	}{
+		{1234.56, PHP, "C0000000000123456"},
+		{-1234.56, PHP, "D0000000000123456"},
+		{1234, "392", "C0000000000001234"},
+	}
+	for _, tt := range tests {
+		if got := netSettlement(tt.amount, tt.currency); got != tt.want {
+			t.Errorf("netSettlement(%v, %s) = %s, want %s", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
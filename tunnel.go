@@ -6,103 +6,740 @@ import (
 	"fmt"
 	"io"
 	"net"
-	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/rs/zerolog/log"
-
 	"github.com/spf13/viper"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 	"golang.org/x/crypto/ssh"
 )
 
-func tunnel(ctx context.Context) (*ssh.Client, net.Listener, error) {
+type Tunnel struct {
+	Id        int       `db:"id" json:"id,omitempty"`
+	Name      string    `db:"name" json:"name"`
+	JumpHosts string    `db:"jump_hosts" json:"jumpHosts"`
+	Forwards  []Forward `json:"forwards"`
+}
+
+type Forward struct {
+	LocalPort  int    `db:"local_port" json:"localPort"`
+	RemoteHost string `db:"remote_host" json:"remoteHost"`
+	RemotePort int    `db:"remote_port" json:"remotePort"`
+}
+
+type TunnelState string
+
+const (
+	TUNNEL_CONNECTING TunnelState = "connecting"
+	TUNNEL_UP         TunnelState = "up"
+	TUNNEL_DEGRADED   TunnelState = "degraded"
+	TUNNEL_DOWN       TunnelState = "down"
+)
+
+const (
+	defaultKeepaliveInterval = 15 * time.Second
+	defaultMaxBackoff        = time.Minute
+	maxMissedKeepalives      = 3
+)
+
+type TunnelStatus struct {
+	Tunnel   Tunnel        `json:"tunnel"`
+	State    TunnelState   `json:"state"`
+	Up       bool          `json:"up"`
+	Attempts int           `json:"attempts"`
+	Missed   int           `json:"missed"`
+	Metrics  TunnelMetrics `json:"metrics"`
+	Error    string        `json:"error,omitempty"`
+}
+
+type jumpHost struct {
+	user    string
+	address string
+}
+
+// tunnelSession is one connection of a tunnel, replaced on every reconnect.
+type tunnelSession struct {
+	clients   []*ssh.Client
+	listeners []net.Listener
+	closeOnce sync.Once
+	failOnce  sync.Once
+	done      chan struct{}
+	err       error
+	connsMu   sync.Mutex
+	conns     map[uint64][]net.Conn
+}
+
+type activeTunnel struct {
+	tunnel  Tunnel
+	hops    []jumpHost
+	mu      sync.Mutex
+	session *tunnelSession
+	stopped chan struct{}
+	metrics *tunnelMetrics
+}
+
+type tunnelManager struct {
+	ctx      context.Context
+	db       *sqlx.DB
+	auth     *sshAuthService
+	hostKeys *hostKeyService
+	mu       sync.Mutex
+	active   map[string]*activeTunnel
+	statuses map[string]TunnelStatus
+	lastUp   bool
+}
+
+func newTunnelManager(ctx context.Context, db *sqlx.DB, auth *sshAuthService, hostKeys *hostKeyService) *tunnelManager {
+	return &tunnelManager{
+		ctx:      ctx,
+		db:       db,
+		auth:     auth,
+		hostKeys: hostKeys,
+		active:   make(map[string]*activeTunnel),
+		statuses: make(map[string]TunnelStatus),
+	}
+}
+
+// parseJumpHosts reads a ProxyJump chain such as "ops@bastion:22,jump2".
+func parseJumpHosts(jumpHosts string) ([]jumpHost, error) {
+	var hops []jumpHost
+	for _, hop := range strings.Split(jumpHosts, ",") {
+		hop = strings.TrimSpace(hop)
+		if len(hop) == 0 {
+			continue
+		}
+		var user string
+		if i := strings.LastIndex(hop, "@"); i >= 0 {
+			user, hop = hop[:i], hop[i+1:]
+		}
+		host, port, err := net.SplitHostPort(hop)
+		if err != nil {
+			host, port = strings.Trim(hop, "[]"), "22"
+		}
+		if _, err := strconv.Atoi(port); err != nil || len(host) == 0 {
+			return nil, fmt.Errorf("invalid jump host %q", hop)
+		}
+		hops = append(hops, jumpHost{user: user, address: net.JoinHostPort(host, port)})
+	}
+	if len(hops) == 0 {
+		return nil, errors.New("tunnel needs at least one jump host")
+	}
+	return hops, nil
+}
 
-	authMethod, err := sshAgentAuth()
+func (m *tunnelManager) getTunnels(profile string) ([]Tunnel, error) {
+	tunnels := []Tunnel{}
+	err := m.db.Select(&tunnels, "SELECT id, name, jump_hosts FROM tunnel WHERE profile = $1 ORDER BY name", profile)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+	for i := range tunnels {
+		forwards := []Forward{}
+		err := m.db.Select(&forwards, "SELECT local_port, remote_host, remote_port FROM tunnel_forward WHERE tunnel_id = $1 ORDER BY id", tunnels[i].Id)
+		if err != nil {
+			return nil, err
+		}
+		tunnels[i].Forwards = forwards
 	}
+	return tunnels, nil
+}
 
-	config := &ssh.ClientConfig{
-		User: viper.GetString("SSH_USERNAME"),
-		Auth: []ssh.AuthMethod{
-			authMethod,
-		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+func (m *tunnelManager) getTunnel(profile string, name string) (Tunnel, error) {
+	tunnels, err := m.getTunnels(profile)
+	if err != nil {
+		return Tunnel{}, err
+	}
+	for _, t := range tunnels {
+		if t.Name == name {
+			return t, nil
+		}
 	}
+	return Tunnel{}, fmt.Errorf("tunnel %q does not exist", name)
+}
 
-	bastionAddr := fmt.Sprintf("%s:%s", viper.GetString("BASTION_HOST"), viper.GetString("BASTION_PORT"))
-	bastionConn, err := ssh.Dial("tcp", bastionAddr, config)
+// saveTunnel creates or replaces the tunnel with the same name.
+func (m *tunnelManager) saveTunnel(profile string, t Tunnel) error {
+	if len(t.Name) == 0 {
+		return errors.New("tunnel name is required")
+	}
+	if _, err := parseJumpHosts(t.JumpHosts); err != nil {
+		return err
+	}
+	for _, f := range t.Forwards {
+		if f.LocalPort <= 0 || f.RemotePort <= 0 || len(f.RemoteHost) == 0 {
+			return fmt.Errorf("invalid forward %d -> %s:%d", f.LocalPort, f.RemoteHost, f.RemotePort)
+		}
+	}
+	tx, err := m.db.Beginx()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO tunnel (profile, name, jump_hosts) VALUES ($1, $2, $3)
+		ON CONFLICT (profile, name) DO UPDATE SET jump_hosts = excluded.jump_hosts`, profile, t.Name, t.JumpHosts)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	var id int
+	err = tx.Get(&id, "SELECT id FROM tunnel WHERE profile = $1 AND name = $2", profile, t.Name)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	_, err = tx.Exec("DELETE FROM tunnel_forward WHERE tunnel_id = $1", id)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to dial bastion host: %v", err)
+		tx.Rollback()
+		return err
 	}
+	for _, f := range t.Forwards {
+		_, err = tx.Exec("INSERT INTO tunnel_forward (tunnel_id, local_port, remote_host, remote_port) VALUES ($1, $2, $3, $4)", id, f.LocalPort, f.RemoteHost, f.RemotePort)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
 
-	localAddr := fmt.Sprintf("localhost:%s", viper.GetString("SSH_LOCAL_PORT"))
-	targetAddr := fmt.Sprintf("%s:%s", viper.GetString("TARGET_HOST"), viper.GetString("SSH_REMOTE_PORT"))
-	listener, err := net.Listen("tcp", localAddr)
+func (m *tunnelManager) deleteTunnel(profile string, name string) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("DELETE FROM tunnel_forward WHERE tunnel_id IN (SELECT id FROM tunnel WHERE profile = $1 AND name = $2)", profile, name)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	_, err = tx.Exec("DELETE FROM tunnel WHERE profile = $1 AND name = $2", profile, name)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create listener on bastion host: %v", err)
+		tx.Rollback()
+		return err
 	}
+	return tx.Commit()
+}
 
-	log.Printf("SSH tunnel established: %s -> %s", localAddr, targetAddr)
-	runtime.EventsEmit(ctx, "tunnel", true)
+func (m *tunnelManager) clientConfig(hop jumpHost) (*ssh.ClientConfig, func(), error) {
+	authMethods, closeAuth, err := m.auth.authMethods()
+	if err != nil {
+		return nil, nil, err
+	}
+	hostKeyCallback, err := m.hostKeys.callback()
+	if err != nil {
+		closeAuth()
+		return nil, nil, err
+	}
+	user := hop.user
+	if len(user) == 0 {
+		user = viper.GetString("SSH_USERNAME")
+	}
+	return &ssh.ClientConfig{
+		User:            user,
+		Auth:            authMethods,
+		HostKeyCallback: hostKeyCallback,
+	}, closeAuth, nil
+}
 
-	go func() {
-		for {
-			localConn, err := listener.Accept()
+// dialChain connects to every jump host in order, each one through the
+// previous, and returns the clients from first to last hop.
+func (m *tunnelManager) dialChain(hops []jumpHost) ([]*ssh.Client, error) {
+	first, err := newDialer(m.auth.secrets, nil)
+	if err != nil {
+		return nil, err
+	}
+	var clients []*ssh.Client
+	closeAll := func() {
+		for i := len(clients) - 1; i >= 0; i-- {
+			clients[i].Close()
+		}
+	}
+	for _, hop := range hops {
+		config, closeAuth, err := m.clientConfig(hop)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		var client *ssh.Client
+		var conn net.Conn
+		if len(clients) == 0 {
+			conn, err = first.dial(hop.address, defaultTimeout)
+		} else {
+			conn, err = clients[len(clients)-1].Dial("tcp", hop.address)
+		}
+		if err == nil {
+			var c ssh.Conn
+			var chans <-chan ssh.NewChannel
+			var reqs <-chan *ssh.Request
+			// channels through a previous hop have no deadline, close them instead
+			var timer *time.Timer
+			if conn.SetDeadline(time.Now().Add(defaultTimeout)) != nil {
+				timer = time.AfterFunc(defaultTimeout, func() { conn.Close() })
+			}
+			c, chans, reqs, err = ssh.NewClientConn(conn, hop.address, config)
+			if timer != nil {
+				timer.Stop()
+			}
+			conn.SetDeadline(time.Time{})
 			if err != nil {
-				fmt.Println("Failed to accept connection:", err)
-				listener.Close()
-				bastionConn.Close()
-				runtime.EventsEmit(ctx, "tunnel", false)
+				conn.Close()
+			} else {
+				client = ssh.NewClient(c, chans, reqs)
+			}
+		}
+		closeAuth()
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("failed to dial jump host %s: %w", hop.address, err)
+		}
+		clients = append(clients, client)
+	}
+	return clients, nil
+}
+
+func (m *tunnelManager) start(profile string, name string) error {
+	t, err := m.getTunnel(profile, name)
+	if err != nil {
+		return err
+	}
+	hops, err := parseJumpHosts(t.JumpHosts)
+	if err != nil {
+		return err
+	}
+	active := &activeTunnel{tunnel: t, hops: hops, stopped: make(chan struct{}), metrics: &tunnelMetrics{}}
+	m.mu.Lock()
+	_, running := m.active[name]
+	if !running {
+		m.active[name] = active
+	}
+	m.mu.Unlock()
+	if running {
+		return fmt.Errorf("tunnel %q is already running", name)
+	}
+
+	m.setStatus(t, TUNNEL_CONNECTING, 0, 0, nil)
+	session, err := m.connect(active)
+	if err != nil {
+		m.mu.Lock()
+		if m.active[name] == active {
+			delete(m.active, name)
+		}
+		m.mu.Unlock()
+		m.setStatus(t, TUNNEL_DOWN, 0, 0, err)
+		return err
+	}
+	if !active.attach(session) {
+		session.close()
+		return fmt.Errorf("tunnel %q was stopped", name)
+	}
+	m.report(active, TUNNEL_UP, 0, 0, nil)
+	go m.supervise(active, session)
+	return nil
+}
+
+// connect dials the jump host chain and listens on the forwarded ports.
+func (m *tunnelManager) connect(active *activeTunnel) (*tunnelSession, error) {
+	t := active.tunnel
+	clients, err := m.dialChain(active.hops)
+	if err != nil {
+		return nil, err
+	}
+	session := &tunnelSession{clients: clients, done: make(chan struct{}), conns: make(map[uint64][]net.Conn)}
+	for _, f := range t.Forwards {
+		localAddr := net.JoinHostPort("localhost", strconv.Itoa(f.LocalPort))
+		listener, err := net.Listen("tcp", localAddr)
+		if err != nil {
+			session.close()
+			return nil, fmt.Errorf("failed to create listener on %s: %w", localAddr, err)
+		}
+		session.listeners = append(session.listeners, listener)
+	}
+
+	for _, client := range clients {
+		go func(client *ssh.Client) {
+			err := client.Wait()
+			if err == nil {
+				err = io.EOF
+			}
+			session.fail(fmt.Errorf("SSH connection to %s lost: %w", client.RemoteAddr(), err))
+		}(client)
+	}
+	last := clients[len(clients)-1]
+	for i, listener := range session.listeners {
+		f := t.Forwards[i]
+		targetAddr := net.JoinHostPort(f.RemoteHost, strconv.Itoa(f.RemotePort))
+		log.Printf("SSH tunnel %s established: %s -> %s via %s", t.Name, listener.Addr(), targetAddr, t.JumpHosts)
+		go m.accept(active, session, listener, last, targetAddr)
+	}
+	return session, nil
+}
+
+func (m *tunnelManager) accept(active *activeTunnel, session *tunnelSession, listener net.Listener, client *ssh.Client, targetAddr string) {
+	for {
+		localConn, err := listener.Accept()
+		if err != nil {
+			session.fail(fmt.Errorf("listener %s stopped: %w", listener.Addr(), err))
+			return
+		}
+
+		go session.forward(active.tunnel.Name, active.metrics, client, localConn, targetAddr)
+	}
+}
+
+// supervise reconnects a running tunnel with backoff until it is stopped.
+func (m *tunnelManager) supervise(active *activeTunnel, session *tunnelSession) {
+	t := active.tunnel
+	for {
+		err := m.monitor(active, session)
+		session.close()
+		if active.isStopped() {
+			return
+		}
+		log.Error().Err(err).Msgf("tunnel %s is down, reconnecting", t.Name)
+		for attempt := 1; ; attempt++ {
+			m.report(active, TUNNEL_DOWN, attempt, 0, err)
+			if !active.sleep(backoff(attempt)) {
+				return
+			}
+			m.report(active, TUNNEL_CONNECTING, attempt, 0, err)
+			session, err = m.connect(active)
+			if err == nil {
 				break
 			}
+			log.Error().Err(err).Msgf("tunnel %s reconnect attempt %d failed", t.Name, attempt)
+		}
+		if !active.attach(session) {
+			session.close()
+			return
+		}
+		log.Printf("tunnel %s reconnected", t.Name)
+		m.report(active, TUNNEL_UP, 0, 0, nil)
+	}
+}
 
-			go handleLocalConnection(bastionConn, localConn, targetAddr)
+// monitor sends keepalives on the last hop every TUNNEL_KEEPALIVE_INTERVAL
+// and takes the tunnel down after maxMissedKeepalives.
+func (m *tunnelManager) monitor(active *activeTunnel, session *tunnelSession) error {
+	interval := time.Duration(viper.GetInt("TUNNEL_KEEPALIVE_INTERVAL")) * time.Second
+	if interval <= 0 {
+		interval = defaultKeepaliveInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	missed := 0
+	for {
+		select {
+		case <-active.stopped:
+			return nil
+		case <-session.done:
+			return session.err
+		case <-ticker.C:
+			err := session.keepalive(interval)
+			if err == nil {
+				if missed > 0 {
+					missed = 0
+					m.report(active, TUNNEL_UP, 0, 0, nil)
+				}
+				continue
+			}
+			missed++
+			if missed >= maxMissedKeepalives {
+				return fmt.Errorf("%d keepalives missed: %w", missed, err)
+			}
+			m.report(active, TUNNEL_DEGRADED, 0, missed, err)
 		}
+	}
+}
+
+func backoff(attempt int) time.Duration {
+	maxBackoff := time.Duration(viper.GetInt("TUNNEL_MAX_BACKOFF")) * time.Second
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+	if attempt > 16 {
+		return maxBackoff
+	}
+	d := time.Second << (attempt - 1)
+	if d > maxBackoff {
+		return maxBackoff
+	}
+	return d
+}
+
+func (s *tunnelSession) keepalive(timeout time.Duration) error {
+	result := make(chan error, 1)
+	go func() {
+		_, _, err := s.clients[len(s.clients)-1].SendRequest("keepalive@openssh.com", true, nil)
+		result <- err
 	}()
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(timeout):
+		return errors.New("keepalive timed out")
+	}
+}
+
+// fail records the first reason the session died and wakes up the monitor.
+func (s *tunnelSession) fail(err error) {
+	s.failOnce.Do(func() {
+		s.err = err
+		close(s.done)
+	})
+}
+
+func (s *tunnelSession) closed() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *tunnelSession) close() {
+	s.closeOnce.Do(func() {
+		for _, l := range s.listeners {
+			l.Close()
+		}
+		s.closeConns()
+		for i := len(s.clients) - 1; i >= 0; i-- {
+			s.clients[i].Close()
+		}
+	})
+}
+
+// attach makes the session current, unless the tunnel has been stopped.
+func (t *activeTunnel) attach(session *tunnelSession) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.isStopped() {
+		return false
+	}
+	t.session = session
+	return true
+}
+
+func (t *activeTunnel) isStopped() bool {
+	select {
+	case <-t.stopped:
+		return true
+	default:
+		return false
+	}
+}
 
-	return bastionConn, listener, nil
+func (t *activeTunnel) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-t.stopped:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (t *activeTunnel) stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.isStopped() {
+		return
+	}
+	close(t.stopped)
+	if t.session != nil {
+		t.session.close()
+	}
+}
+
+// client returns the last hop of a running tunnel, or of the first running
+// one when name is empty.
+func (m *tunnelManager) client(name string) (*ssh.Client, error) {
+	m.mu.Lock()
+	tunnels := make([]*activeTunnel, 0, len(m.active))
+	for n, t := range m.active {
+		if len(name) == 0 || n == name {
+			tunnels = append(tunnels, t)
+		}
+	}
+	m.mu.Unlock()
+	sort.Slice(tunnels, func(i, j int) bool { return tunnels[i].tunnel.Name < tunnels[j].tunnel.Name })
+	for _, t := range tunnels {
+		t.mu.Lock()
+		session := t.session
+		t.mu.Unlock()
+		if session != nil && !session.closed() {
+			return session.clients[len(session.clients)-1], nil
+		}
+	}
+	if len(name) > 0 {
+		return nil, fmt.Errorf("tunnel %q is not up", name)
+	}
+	return nil, errors.New("no tunnel is up, start one to use the SSH dialer")
+}
+
+func (m *tunnelManager) stop(name string) error {
+	m.mu.Lock()
+	active, ok := m.active[name]
+	delete(m.active, name)
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("tunnel %q is not running", name)
+	}
+	active.stop()
+	log.Printf("tunnel %s stopped", name)
+	m.setStatus(active.tunnel, TUNNEL_DOWN, 0, 0, nil)
+	return nil
+}
+
+func (m *tunnelManager) stopAll() {
+	m.mu.Lock()
+	names := make([]string, 0, len(m.active))
+	for name := range m.active {
+		names = append(names, name)
+	}
+	m.mu.Unlock()
+	for _, name := range names {
+		m.stop(name)
+	}
+	m.mu.Lock()
+	m.statuses = make(map[string]TunnelStatus)
+	m.mu.Unlock()
 }
 
-func publicKeyFile(file string) (ssh.AuthMethod, error) {
-	key, err := os.ReadFile(file)
+// setStatus emits "tunnel:status", and "tunnel" when the overall state
+// changes.
+func (m *tunnelManager) setStatus(t Tunnel, state TunnelState, attempts int, missed int, err error) {
+	status := TunnelStatus{
+		Tunnel:   t,
+		State:    state,
+		Up:       state == TUNNEL_UP || state == TUNNEL_DEGRADED,
+		Attempts: attempts,
+		Missed:   missed,
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to read private key: %w", err)
+		status.Error = err.Error()
 	}
+	m.mu.Lock()
+	m.statuses[t.Name] = status
+	up := m.up()
+	changed := up != m.lastUp
+	m.lastUp = up
+	m.mu.Unlock()
+	runtime.EventsEmit(m.ctx, "tunnel:status", status)
+	if changed {
+		runtime.EventsEmit(m.ctx, "tunnel", up)
+	}
+}
 
-	passPhrase := viper.GetString("SSH_PASSPHRASE")
-	var signer ssh.Signer
+// report sets the status of a tunnel unless it was stopped.
+func (m *tunnelManager) report(active *activeTunnel, state TunnelState, attempts int, missed int, err error) {
+	m.mu.Lock()
+	running := m.active[active.tunnel.Name] == active
+	m.mu.Unlock()
+	if running && !active.isStopped() {
+		m.setStatus(active.tunnel, state, attempts, missed, err)
+	}
+}
 
-	if len(passPhrase) > 0 {
-		signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(viper.GetString("SSH_PASSPHRASE")))
-	} else {
-		signer, err = ssh.ParsePrivateKey(key)
+// up reports whether any running tunnel is usable. m.mu must be held.
+func (m *tunnelManager) up() bool {
+	for name := range m.active {
+		if m.statuses[name].Up {
+			return true
+		}
 	}
+	return false
+}
 
+func (m *tunnelManager) status(profile string) ([]TunnelStatus, error) {
+	tunnels, err := m.getTunnels(profile)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse private key: %w", err)
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	statuses := make([]TunnelStatus, 0, len(tunnels))
+	for _, t := range tunnels {
+		status, ok := m.statuses[t.Name]
+		if !ok {
+			status = TunnelStatus{State: TUNNEL_DOWN}
+		}
+		status.Tunnel = t
+		if active, ok := m.active[t.Name]; ok {
+			status.Metrics = active.metrics.snapshot()
+		}
+		statuses = append(statuses, status)
 	}
+	return statuses, nil
+}
 
-	return ssh.PublicKeys(signer), nil
+func (m *tunnelManager) ping() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.up()
 }
 
-func handleLocalConnection(client *ssh.Client, localConn net.Conn, targetAddr string) {
-	targetConn, err := client.Dial("tcp", targetAddr)
+func (a *App) GetTunnels() ([]TunnelStatus, error) {
+	profile, err := a.configService.getActiveProfile()
 	if err != nil {
-		log.Printf("Failed to connect to target host: %v", err)
-		return
+		log.Error().Err(err).Msg("")
+		return nil, err
+	}
+	statuses, err := a.tunnelManager.status(profile)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return nil, err
 	}
+	return statuses, nil
+}
 
-	go io.Copy(localConn, targetConn)
-	go io.Copy(targetConn, localConn)
+func (a *App) SaveTunnel(t Tunnel) error {
+	profile, err := a.configService.getActiveProfile()
+	if err == nil {
+		err = a.tunnelManager.saveTunnel(profile, t)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	return nil
 }
 
-func sshAgentAuth() (ssh.AuthMethod, error) {
-	sshKey := viper.GetString("SSH_KEY")
+func (a *App) DeleteTunnel(name string) error {
+	a.tunnelManager.stop(name)
+	profile, err := a.configService.getActiveProfile()
+	if err == nil {
+		err = a.tunnelManager.deleteTunnel(profile, name)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	return nil
+}
 
-	if len(sshKey) > 0 {
-		return publicKeyFile(sshKey)
+func (a *App) StartTunnel(name string) error {
+	profile, err := a.configService.getActiveProfile()
+	if err == nil {
+		err = a.tunnelManager.start(profile, name)
 	}
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	return nil
+}
 
-	return nil, errors.New("missing SSH_KEY, please configure it in the settings")
+func (a *App) StopTunnel(name string) error {
+	err := a.tunnelManager.stop(name)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return err
+	}
+	return nil
 }
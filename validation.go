@@ -0,0 +1,358 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type ValidationError struct {
+	Errors []FieldError `json:"errors"`
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, f := range e.Errors {
+		messages = append(messages, fmt.Sprintf("%s: %s", f.Field, f.Message))
+	}
+	return strings.Join(messages, "; ")
+}
+
+type switchRules struct {
+	terminalNameLength int
+	accountLength      int
+	transactions       []Transaction
+}
+
+var allTransactions = []Transaction{WITHDRAW, BAL_INQ, FT, IBFTC, IBFTD, ELOAD, BILLS, PURCHASE,
+	PRE_AUTH, INCREMENTAL_AUTH, PRE_AUTH_COMPLETION, PRE_AUTH_CANCEL, DEPOSIT, CHEQUE_DEPOSIT,
+	MINI_STATEMENT, PIN_CHANGE, CARDLESS_WITHDRAW}
+
+// Postilion limits of fields 43, 102 and 103 as PostBridge has no spec.
+const (
+	postbridgeTerminalNameLength = 40
+	postbridgeAccountLength      = 28
+)
+
+var validationRules = map[AtmSwitch]switchRules{
+	CORTEX: {
+		terminalNameLength: fisGlobalSpec.Fields[43].Spec().Length,
+		accountLength:      fisGlobalSpec.Fields[102].Spec().Length,
+		transactions:       supportedTransactions(cortex.getProcessCode),
+	},
+	NARADA: {
+		terminalNameLength: naradaSpec.Fields[43].Spec().Length,
+		accountLength:      naradaSpec.Fields[102].Spec().Length,
+		transactions:       supportedTransactions(narada.getProcessCode),
+	},
+	POSTBRIDGE: {
+		terminalNameLength: postbridgeTerminalNameLength,
+		accountLength:      postbridgeAccountLength,
+		transactions:       supportedTransactions(postbridge.getProcessCode),
+	},
+}
+
+// supportedTransactions lists the transactions a switch has a processing
+// code for.
+func supportedTransactions(getProcessCode func(Message) (string, error)) []Transaction {
+	var transactions []Transaction
+	for _, t := range allTransactions {
+		if _, err := getProcessCode(Message{Transaction: t}); err == nil {
+			transactions = append(transactions, t)
+		}
+	}
+	return transactions
+}
+
+var allowedDevices = map[Transaction][]Device{
+	WITHDRAW:            {ATM},
+	PURCHASE:            {POS},
+	PRE_AUTH:            {POS},
+	INCREMENTAL_AUTH:    {POS},
+	PRE_AUTH_COMPLETION: {POS},
+	PRE_AUTH_CANCEL:     {POS},
+	DEPOSIT:             {ATM},
+	CHEQUE_DEPOSIT:      {ATM},
+	MINI_STATEMENT:      {ATM},
+	PIN_CHANGE:          {ATM},
+	CARDLESS_WITHDRAW:   {ATM},
+}
+
+// inquiries are the transactions sent without an amount.
+var inquiries = []Transaction{BAL_INQ, MINI_STATEMENT, PIN_CHANGE}
+
+var allowedChannels = map[Transaction][]Channel{
+	FT:    {ON_US},
+	IBFTC: {ON_US, OFF_US},
+	IBFTD: {ON_US, OFF_US},
+	ELOAD: {ON_US, OFF_US},
+	BILLS: {ON_US, OFF_US},
+}
+
+const (
+	minPanLength          = 12
+	maxPanLength          = 19
+	maxAmountDigits       = 12
+	maxInstitutionLength  = 11
+	terminalIdLength      = 8
+	maxDepositItems       = 999
+	maxChequeNumberLength = 20
+)
+
+func validateMessage(message Message) error {
+	errs := checkMessage(message)
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func checkMessage(message Message) []FieldError {
+	var errs []FieldError
+	add := func(field string, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	rules, ok := validationRules[message.Switch]
+	if !ok {
+		add("switch", "switch %q is not supported", message.Switch)
+		rules = validationRules[CORTEX]
+	}
+
+	if !contains(allTransactions, message.Transaction) {
+		add("transaction", "unknown transaction %q", message.Transaction)
+	} else if !contains(rules.transactions, message.Transaction) {
+		add("transaction", "%s is not supported by %s", message.Transaction, message.Switch)
+	} else if isFollowUp(message.Transaction) && message.AuthorizationId == 0 {
+		add("transaction", "%s must be sent from a pre-auth", message.Transaction)
+	}
+
+	switch message.Device {
+	case ATM, POS, NAD:
+		if devices, ok := allowedDevices[message.Transaction]; ok && !contains(devices, message.Device) {
+			add("device", "%s is not allowed on device %s", message.Transaction, message.Device)
+		}
+	default:
+		add("device", "unknown device %q", message.Device)
+	}
+
+	switch message.Channel {
+	case ON_US, OFF_US, MASTERCARD:
+		if channels, ok := allowedChannels[message.Transaction]; ok && !contains(channels, message.Channel) {
+			add("channel", "%s is not allowed on channel %s", message.Transaction, message.Channel)
+		}
+	default:
+		add("channel", "unknown channel %q", message.Channel)
+	}
+
+	if _, ok := getCurrency(message.CurrencyCode); !ok {
+		add("currencyCode", "unknown currency code %q", message.CurrencyCode)
+	}
+
+	if len(message.BillingCurrencyCode) > 0 {
+		if _, ok := getCurrency(message.BillingCurrencyCode); !ok {
+			add("billingCurrencyCode", "unknown currency code %q", message.BillingCurrencyCode)
+		} else if message.BillingCurrencyCode != message.CurrencyCode && message.BillingAmount == 0 && message.ConversionRate == 0 {
+			add("billingCurrencyCode", "billing amount or conversion rate is required when the billing currency differs")
+		}
+	}
+
+	billing, billingCurrency := billingAmount(message)
+	if msg := checkAmount(message.BillingAmount, billingCurrency); msg != "" {
+		add("billingAmount", "%s", msg)
+	} else if message.BillingAmount == 0 && message.ConversionRate != 0 {
+		if msg := checkAmount(billing, billingCurrency); msg != "" {
+			add("conversionRate", "converted billing amount: %s", msg)
+		}
+	}
+
+	if message.ConversionRate != 0 {
+		if _, err := serializeConversionRate(message.ConversionRate); err != nil {
+			add("conversionRate", "%s", err)
+		}
+	}
+
+	pan := message.PrimaryAccountNumber
+	cardless := message.Transaction == CARDLESS_WITHDRAW
+	switch {
+	case len(pan) == 0 && cardless:
+	case len(pan) > 0 && cardless:
+		add("primaryAccountNumber", "%s is sent without a card", message.Transaction)
+	case len(pan) == 0:
+		add("primaryAccountNumber", "primary account number is required")
+	case !isNumeric(pan):
+		add("primaryAccountNumber", "primary account number must be numeric")
+	case len(pan) < minPanLength || len(pan) > maxPanLength:
+		add("primaryAccountNumber", "primary account number must be %d to %d digits", minPanLength, maxPanLength)
+	case !luhnValid(pan):
+		add("primaryAccountNumber", "primary account number fails the Luhn check")
+	}
+
+	if msg := checkAmount(message.TransactionAmount, message.CurrencyCode); msg != "" {
+		add("transactionAmount", "%s", msg)
+	} else if !contains(inquiries, message.Transaction) && message.TransactionAmount == 0 {
+		add("transactionAmount", "transaction amount is required for %s", message.Transaction)
+	}
+
+	if msg := checkAmount(message.TransactionFee, message.CurrencyCode); msg != "" {
+		add("transactionFee", "%s", msg)
+	}
+
+	checkCode := func(field string, value string, required bool) {
+		switch {
+		case len(value) == 0:
+			if required {
+				add(field, "institution code is required")
+			}
+		case !isNumeric(value):
+			add(field, "institution code must be numeric")
+		case len(value) > maxInstitutionLength:
+			add(field, "institution code must be at most %d digits", maxInstitutionLength)
+		}
+	}
+	checkCode("acquiringInstitutionCode", message.AcquiringInstitutionCode, true)
+	checkCode("receivingInstitutionCode", message.ReceivingInstitutionCode, false)
+
+	if len(message.TerminalID) != terminalIdLength {
+		add("terminalId", "terminal ID must contain %d characters", terminalIdLength)
+	}
+
+	if len(message.TerminalNameAndLocation) > rules.terminalNameLength {
+		add("terminalNameAndLocation", "terminal name and location must be at most %d characters for %s", rules.terminalNameLength, message.Switch)
+	}
+
+	checkAccount := func(field string, value string, required bool) {
+		switch {
+		case len(value) == 0:
+			if required {
+				add(field, "account is required for %s", message.Transaction)
+			}
+		case len(value) > rules.accountLength:
+			add(field, "account must be at most %d characters", rules.accountLength)
+		}
+	}
+	checkAccount("sourceAccount", message.SourceAccount, message.Transaction == FT || message.Transaction == IBFTD)
+	checkAccount("destinationAccount", message.DestinationAccount, message.Transaction == FT || message.Transaction == IBFTC)
+
+	switch {
+	case len(message.ReferenceCode) == 0:
+		if cardless {
+			add("referenceCode", "reference code is required for %s", message.Transaction)
+		}
+	case len(message.ReferenceCode) > maxReferenceCodeLength:
+		add("referenceCode", "reference code must be at most %d characters", maxReferenceCodeLength)
+	}
+	switch {
+	case len(message.MobileNumber) == 0:
+		if cardless {
+			add("mobileNumber", "mobile number is required for %s", message.Transaction)
+		}
+	case !isNumeric(message.MobileNumber) || len(message.MobileNumber) < minMobileNumberLength || len(message.MobileNumber) > maxMobileNumberLength:
+		add("mobileNumber", "mobile number must be %d to %d digits", minMobileNumberLength, maxMobileNumberLength)
+	}
+	switch {
+	case len(message.Otp) == 0:
+		// the OTP is not stored, reversals are sent without it
+		if cardless && message.ParentId == 0 {
+			add("otp", "OTP is required for %s", message.Transaction)
+		}
+	case !isNumeric(message.Otp) || len(message.Otp) < minOtpLength || len(message.Otp) > maxOtpLength:
+		add("otp", "OTP must be %d to %d digits", minOtpLength, maxOtpLength)
+	}
+
+	checkPin := func(field string, value string, required bool) {
+		switch {
+		case len(value) == 0:
+			if required {
+				add(field, "PIN is required for %s", message.Transaction)
+			}
+		case !isNumeric(value) || len(value) < minPinLength || len(value) > maxPinLength:
+			add(field, "PIN must be %d to %d digits", minPinLength, maxPinLength)
+		}
+	}
+	checkPin("pin", message.Pin, message.Transaction == PIN_CHANGE)
+	checkPin("newPin", message.NewPin, message.Transaction == PIN_CHANGE)
+	if len(message.NewPin) > 0 && message.NewPin == message.Pin {
+		add("newPin", "new PIN must differ from the current PIN")
+	}
+
+	if message.DepositItems < 0 || message.DepositItems > maxDepositItems {
+		add("depositItems", "deposit items must be from 0 to %d", maxDepositItems)
+	}
+	switch {
+	case message.Transaction == CHEQUE_DEPOSIT && len(message.ChequeNumber) == 0:
+		add("chequeNumber", "cheque number is required for %s", message.Transaction)
+	case len(message.ChequeNumber) > 0 && !isNumeric(message.ChequeNumber):
+		add("chequeNumber", "cheque number must be numeric")
+	case len(message.ChequeNumber) > maxChequeNumberLength:
+		add("chequeNumber", "cheque number must be at most %d digits", maxChequeNumberLength)
+	}
+
+	switch message.TargetBank {
+	case "":
+		if message.Transaction == IBFTC {
+			add("targetBank", "target bank is required for %s", message.Transaction)
+		}
+	case OTHER_BANK, INTER_SYSTEM:
+	default:
+		add("targetBank", "unknown target bank %q", message.TargetBank)
+	}
+
+	return errs
+}
+
+func checkAmount(amount float64, currency Currency) string {
+	switch {
+	case math.IsNaN(amount) || math.IsInf(amount, 0):
+		return "amount must be a number"
+	case amount < 0:
+		return "amount must not be negative"
+	case amount*math.Pow10(currencyExponent(currency)) >= math.Pow10(maxAmountDigits):
+		return fmt.Sprintf("amount must fit in %d digits", maxAmountDigits)
+	}
+	return ""
+}
+
+func luhnValid(number string) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		digit := int(number[i] - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func isNumeric(input string) bool {
+	for _, c := range input {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return len(input) > 0
+}
+
+func contains[T comparable](values []T, value T) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *App) ValidateMessage(message Message) []FieldError {
+	return checkMessage(message)
+}
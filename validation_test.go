@@ -0,0 +1,143 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLuhnValid(t *testing.T) {
+	tests := map[string]bool{
+		"4111111111111111":    true,
+		"4111111111111112":    false,
+		"5500000000000004":    true,
+		"6011000990139424":    true,
+		"6011000990139423":    false,
+		"3530111333300000":    true,
+		"4222222222222220000": false,
+	}
+	for number, want := range tests {
+		if got := luhnValid(number); got != want {
+			t.Errorf("luhnValid(%s) = %v, want %v", number, got, want)
+		}
+	}
+}
+
+func TestCheckMessage(t *testing.T) {
+	valid := func(sw AtmSwitch, transaction Transaction) Message {
+		return Message{
+			Switch:                   sw,
+			Transaction:              transaction,
+			Device:                   ATM,
+			Channel:                  ON_US,
+			CurrencyCode:             PHP,
+			PrimaryAccountNumber:     "4111111111111111",
+			TransactionAmount:        1000,
+			AcquiringInstitutionCode: "1234",
+			TerminalID:               "TERM0001",
+		}
+	}
+	tests := []struct {
+		name    string
+		message func(m *Message)
+		want    []string
+	}{
+		{"valid", func(m *Message) {}, nil},
+		{"unknown switch", func(m *Message) { m.Switch = "VISA" }, []string{"switch"}},
+		{"follow-up without pre-auth", func(m *Message) { m.Transaction, m.Device = PRE_AUTH_COMPLETION, POS }, []string{"transaction"}},
+		{"withdraw on POS", func(m *Message) { m.Device = POS }, []string{"device"}},
+		{"FT off-us", func(m *Message) {
+			m.Transaction, m.Channel, m.SourceAccount, m.DestinationAccount = FT, OFF_US, "1", "2"
+		}, []string{"channel"}},
+		{"bad Luhn", func(m *Message) { m.PrimaryAccountNumber = "4111111111111112" }, []string{"primaryAccountNumber"}},
+		{"short PAN", func(m *Message) { m.PrimaryAccountNumber = "41111111" }, []string{"primaryAccountNumber"}},
+		{"no amount", func(m *Message) { m.TransactionAmount = 0 }, []string{"transactionAmount"}},
+		{"inquiry without amount", func(m *Message) { m.Transaction, m.TransactionAmount = BAL_INQ, 0 }, nil},
+		{"amount too large", func(m *Message) { m.TransactionAmount = 1e10 }, []string{"transactionAmount"}},
+		{"unknown currency", func(m *Message) { m.CurrencyCode = "999" }, []string{"currencyCode"}},
+		{"billing without rate", func(m *Message) { m.BillingCurrencyCode = USD }, []string{"billingCurrencyCode"}},
+		{"billing with rate", func(m *Message) { m.BillingCurrencyCode, m.ConversionRate = USD, 0.0178 }, nil},
+		{"conversion rate too small", func(m *Message) { m.BillingCurrencyCode, m.ConversionRate = USD, 0.00000001 }, []string{"conversionRate"}},
+		{"converted billing too large", func(m *Message) {
+			m.TransactionAmount, m.BillingCurrencyCode, m.ConversionRate = 1e9, USD, 1000
+		}, []string{"conversionRate"}},
+		{"FT without accounts", func(m *Message) { m.Transaction = FT }, []string{"sourceAccount", "destinationAccount"}},
+		{"IBFTC without bank", func(m *Message) { m.Transaction, m.DestinationAccount = IBFTC, "1" }, []string{"targetBank"}},
+		{"IBFTC", func(m *Message) { m.Transaction, m.DestinationAccount, m.TargetBank = IBFTC, "1", OTHER_BANK }, nil},
+		{"IBFTD without source", func(m *Message) { m.Transaction = IBFTD }, []string{"sourceAccount"}},
+		{"cardless with card", func(m *Message) {
+			m.Transaction, m.ReferenceCode, m.MobileNumber, m.Otp = CARDLESS_WITHDRAW, "REF123", "639171234567", "1234"
+		}, []string{"primaryAccountNumber"}},
+		{"cardless", func(m *Message) {
+			m.Transaction, m.PrimaryAccountNumber, m.ReferenceCode, m.MobileNumber, m.Otp = CARDLESS_WITHDRAW, "", "REF123", "639171234567", "1234"
+		}, nil},
+		{"cardless without data", func(m *Message) {
+			m.Transaction, m.PrimaryAccountNumber = CARDLESS_WITHDRAW, ""
+		}, []string{"referenceCode", "mobileNumber", "otp"}},
+		{"cardless reversal without OTP", func(m *Message) {
+			m.Transaction, m.PrimaryAccountNumber, m.ReferenceCode, m.MobileNumber, m.ParentId = CARDLESS_WITHDRAW, "", "REF123", "639171234567", 1
+		}, nil},
+		{"bad OTP", func(m *Message) { m.Otp = "12a4" }, []string{"otp"}},
+		{"PIN change without PINs", func(m *Message) { m.Transaction = PIN_CHANGE }, []string{"pin", "newPin"}},
+		{"same PIN", func(m *Message) { m.Transaction, m.Pin, m.NewPin = PIN_CHANGE, "1234", "1234" }, []string{"newPin"}},
+		{"short PIN", func(m *Message) { m.Pin = "123" }, []string{"pin"}},
+		{"too many deposit items", func(m *Message) { m.Transaction, m.DepositItems = DEPOSIT, 1000 }, []string{"depositItems"}},
+		{"cheque deposit without number", func(m *Message) { m.Transaction = CHEQUE_DEPOSIT }, []string{"chequeNumber"}},
+		{"bad cheque number", func(m *Message) { m.Transaction, m.ChequeNumber = CHEQUE_DEPOSIT, "12A" }, []string{"chequeNumber"}},
+		{"short terminal ID", func(m *Message) { m.TerminalID = "TERM1" }, []string{"terminalId"}},
+		{"bad institution code", func(m *Message) { m.AcquiringInstitutionCode = "12345678901234" }, []string{"acquiringInstitutionCode"}},
+	}
+	for _, tt := range tests {
+		for _, sw := range []AtmSwitch{CORTEX, NARADA, POSTBRIDGE} {
+			message := valid(sw, WITHDRAW)
+			tt.message(&message)
+			var got []string
+			for _, e := range checkMessage(message) {
+				got = append(got, e.Field)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s on %s: checkMessage() = %v, want %v", tt.name, sw, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestCheckMessageLengths(t *testing.T) {
+	tests := []struct {
+		atmSwitch     AtmSwitch
+		terminalName  int
+		accountLength int
+	}{
+		{CORTEX, 99, 99},
+		{NARADA, 40, 99},
+		{POSTBRIDGE, 40, 28},
+	}
+	for _, tt := range tests {
+		message := Message{
+			Switch:                   tt.atmSwitch,
+			Transaction:              FT,
+			Device:                   ATM,
+			Channel:                  ON_US,
+			CurrencyCode:             PHP,
+			PrimaryAccountNumber:     "4111111111111111",
+			TransactionAmount:        1000,
+			AcquiringInstitutionCode: "1234",
+			TerminalID:               "TERM0001",
+			TerminalNameAndLocation:  strings.Repeat("A", tt.terminalName),
+			SourceAccount:            strings.Repeat("1", tt.accountLength),
+			DestinationAccount:       strings.Repeat("2", tt.accountLength),
+		}
+		if errs := checkMessage(message); len(errs) > 0 {
+			t.Errorf("%s at the limits: checkMessage() = %v", tt.atmSwitch, errs)
+		}
+		message.TerminalNameAndLocation += "A"
+		message.SourceAccount += "1"
+		var got []string
+		for _, e := range checkMessage(message) {
+			got = append(got, e.Field)
+		}
+		if want := []string{"terminalNameAndLocation", "sourceAccount"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s over the limits: checkMessage() = %v, want %v", tt.atmSwitch, got, want)
+		}
+	}
+}